@@ -0,0 +1,52 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AltTextRows is how many rows of a table are included in its alt text
+const AltTextRows = 3
+
+// Accessibility errors
+var (
+	ERR_TEXTONLY_COMMAND = errors.New("Turn images off completely using !textonly on, or back on with !textonly off")
+)
+
+// AltText returns a compact text version of the table to send with its image
+func (t *Table) AltText(lang string) string {
+	lines := []string{fmt.Sprintf("**%s**", t.Title)}
+	if t.Summary != "" {
+		lines = append(lines, t.Summary)
+	}
+
+	if len(t.Rows) > 0 {
+		top := &Table{Headers: t.Headers, Rows: t.Rows}
+		if len(top.Rows) > AltTextRows {
+			top.Rows = top.Rows[:AltTextRows]
+		}
+		lines = append(lines, fmt.Sprintf(Translate(lang, "Top %d of %d rows:"), len(top.Rows), len(t.Rows)))
+		lines = append(lines, Example(top.Text()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textOnly returns true if the user doesn't want any images
+func (b *botResponse) textOnly() bool {
+	return b.Pref("textonly") == "on"
+}
+
+// SetTextOnlyCommand lets users turn off images completely, for screen readers or when images are disabled
+func SetTextOnlyCommand(b *botResponse) error {
+	if len(b.fields) != 2 {
+		return &botError{ERR_TEXTONLY_COMMAND, ""}
+	}
+
+	msg, err := b.setPref("textonly", b.fields[1], ScopeUser)
+	if err != nil {
+		return &botError{ERR_TEXTONLY_COMMAND, ""}
+	}
+	b.PrintToDiscord(msg)
+	return nil
+}
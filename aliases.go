@@ -0,0 +1,156 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Alias errors
+var (
+	ERR_ADDALIAS_COMMAND = errors.New("Add an alias for your server using !addalias {alias} {command} {options}")
+	ERR_DELALIAS_COMMAND = errors.New("Delete an alias for your server using !delalias {alias}")
+	ERR_ALIAS_MISSING    = errors.New("That alias doesn't exist.")
+	ERR_NO_ALIASES       = errors.New("No aliases set. Add one with !addalias")
+)
+
+// GetAlias returns the command an alias stands for in a guild
+func (guild *Guild) GetAlias(name string) ([]string, bool) {
+	target := strings.Fields(guild.Settings.Aliases[name])
+	if len(target) == 0 {
+		return nil, false
+	}
+	return target, true
+}
+
+// SetAlias adds or replaces an alias for a guild
+func (guild *Guild) SetAlias(name, target string) error {
+	if _, ok := cmdMap[name]; ok {
+		return &botError{ERR_CMD_BUILTIN, name}
+	}
+	if _, _, ok := getSuffixCommand(name); ok {
+		return &botError{ERR_CMD_BUILTIN, name}
+	}
+	if _, ok := guild.GetCustomCommand(name); ok {
+		return &botError{ERR_CMD_EXISTS, name}
+	}
+
+	if name == "" || strings.TrimSpace(target) == "" {
+		return &botError{ERR_ADDALIAS_COMMAND, ""}
+	}
+
+	targetName := strings.Fields(target)[0]
+	_, builtin := cmdMap[targetName]
+	_, custom := guild.GetCustomCommand(targetName)
+	if !builtin && !custom {
+		return &botError{ERR_CMD_MISSING, targetName}
+	}
+
+	if guild.Settings.Aliases == nil {
+		guild.Settings.Aliases = make(map[string]string)
+	}
+	guild.Settings.Aliases[name] = target
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// DeleteAlias removes an alias from a guild
+func (guild *Guild) DeleteAlias(name string) error {
+	if _, ok := guild.Settings.Aliases[name]; !ok {
+		return &botError{ERR_ALIAS_MISSING, name}
+	}
+
+	delete(guild.Settings.Aliases, name)
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// CommandAliases returns the sorted aliases a guild has set for a command
+func (guild *Guild) CommandAliases(cmd string) []string {
+	aliases := []string{}
+	if guild == nil {
+		return aliases
+	}
+
+	for alias, target := range guild.Settings.Aliases {
+		if f := strings.Fields(target); len(f) > 0 && f[0] == cmd {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// AddAlias allows the server owner to add an alias for a command
+func AddAlias(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	if len(b.fields) < 3 {
+		return &botError{ERR_ADDALIAS_COMMAND, ""}
+	}
+
+	prefix := guild.Settings.BotPrefix
+	name := customCommandName(b.fields[1], guild)
+	target := customCommandName(b.fields[2], guild)
+	if len(b.fields) > 3 {
+		target += " " + strings.Join(b.fields[3:], " ")
+	}
+
+	err = guild.SetAlias(name, target)
+	if err != nil {
+		return err
+	}
+
+	b.PrintToDiscord(b.T("Alias %s%s now runs %s%s", prefix, name, prefix, target))
+	return nil
+}
+
+// DeleteAliasCommand allows the server owner to remove an alias
+func DeleteAliasCommand(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	if len(b.fields) < 2 {
+		return &botError{ERR_DELALIAS_COMMAND, ""}
+	}
+
+	name := customCommandName(b.fields[1], guild)
+	err = guild.DeleteAlias(name)
+	if err != nil {
+		return err
+	}
+
+	b.PrintToDiscord(b.T("Alias %s deleted!", name))
+	return nil
+}
+
+// ListAliases prints the aliases for a guild to discord
+func ListAliases(b *botResponse) error {
+	if b.guild == nil {
+		return &botError{ERR_NO_GUILD, ""}
+	}
+
+	if len(b.guild.Settings.Aliases) == 0 {
+		return &botError{ERR_NO_ALIASES, ""}
+	}
+
+	prefix := b.guild.Settings.BotPrefix
+	lines := []string{}
+	for alias, target := range b.guild.Settings.Aliases {
+		lines = append(lines, fmt.Sprintf("%s%s → %s%s", prefix, alias, prefix, target))
+	}
+	sort.Strings(lines)
+
+	emb := b.NewEmbed().
+		SetColorRole(ColorInfo).
+		AddField(b.T("Server Aliases"), strings.Join(lines, "\n"))
+	b.addPrefixesField(emb, b.guild)
+	b.PrintEmbedToDiscord(emb.MessageEmbed)
+	return nil
+}
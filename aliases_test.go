@@ -0,0 +1,81 @@
+package haynesbot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// useTempGuildFile points guild settings at an empty file in a temp folder until the returned func is called
+func useTempGuildFile(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "haynesbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldConfig, oldSettings := config, guildSettings
+	config = &configStruct{GuildFile: filepath.Join(dir, "guilds.json")}
+	guildSettings = &GuildSettings{}
+	return func() {
+		config, guildSettings = oldConfig, oldSettings
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	defer useTempGuildFile(t)()
+
+	guild := &Guild{Guild: &discordgo.Guild{ID: "g"}, Settings: GuildSetting{ID: "g", BotPrefix: "!"}}
+	if err := guild.SetAlias("hundo", "raidiv 15"); err != nil {
+		t.Fatalf("SetAlias(hundo) = %v", err)
+	}
+
+	b := &botResponse{guild: guild, fields: []string{"!hundo", "groudon"}}
+	cmd := b.GetCommand("!")
+	if cmd == nil || cmd.Name != "raidiv" {
+		t.Fatalf("!hundo resolved to %v, want raidiv", cmd)
+	}
+	if got := b.fields; len(got) != 3 || got[1] != "15" || got[2] != "groudon" {
+		t.Errorf("!hundo fields = %v, want [raidiv 15 groudon]", got)
+	}
+
+	if err := guild.DeleteAlias("hundo"); err != nil {
+		t.Fatalf("DeleteAlias(hundo) = %v", err)
+	}
+	if _, ok := guild.GetAlias("hundo"); ok {
+		t.Error("hundo is still an alias after deleting it")
+	}
+	if err := guild.DeleteAlias("hundo"); err == nil {
+		t.Error("Deleting a missing alias worked")
+	}
+}
+
+func TestAliasCollisions(t *testing.T) {
+	defer useTempGuildFile(t)()
+
+	guild := &Guild{Guild: &discordgo.Guild{ID: "g"}, Settings: GuildSetting{ID: "g", BotPrefix: "!"}}
+	if err := guild.AddCustomCommand(CustomCommand{Name: "rules", Response: "Be nice"}); err != nil {
+		t.Fatalf("AddCustomCommand(rules) = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"raidiv", ERR_CMD_BUILTIN},
+		{"raidcp", ERR_CMD_BUILTIN},
+		{"rules", ERR_CMD_EXISTS},
+	}
+	for _, test := range tests {
+		err := guild.SetAlias(test.name, "iv")
+		if be, ok := err.(*botError); !ok || be.err != test.want {
+			t.Errorf("SetAlias(%s) = %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	if err := guild.SetAlias("stats", "nothing"); err == nil {
+		t.Error("Alias for a missing command worked")
+	}
+}
@@ -5,14 +5,13 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/haynesherway/pogo"
 )
 
 // BotID for discord
@@ -23,17 +22,17 @@ var (
 
 // Error printouts
 var (
-	ERR_CP_COMMAND                = errors.New("CP command needs to be formatted like this: !cp {pokemon} {level} {attack iv} {defense iv} {stamina iv}")
-	ERR_IV_COMMAND                = errors.New("IV command needs to be formatted like this: !iv {pokemon} {cp} {hp} {level} or !iv {pokemon} {cp} {hp}")
+	ERR_CP_COMMAND                = errors.New("CP command needs to be formatted like this: !cp {pokemon} {level} {attack iv} {defense iv} {stamina iv} {'bb'}")
+	ERR_IV_COMMAND                = errors.New("IV command needs to be formatted like this: !iv {pokemon} {cp} {hp} {level|stardust} {adh} {'bb'} or !iv {pokemon} {cp} {hp}")
 	ERR_RAIDCP_COMMAND            = errors.New("Raid CP command needs to be formatted like this: !raidcp {pokemon} or !raidcp {pokemon} {cp}")
-	ERR_RAIDCHART_COMMAND         = errors.New("Raid CP Chart command needs to be formatted like this: !raidcpchart {pokemon}")
+	ERR_RAIDCHART_COMMAND         = errors.New("Raid CP Chart command needs to be formatted like this: !raidchart {pokemon} {'full'} {'svg'}")
 	ERR_MAXCP_COMMAND             = errors.New("Max CP command needs to be formatted like this: !maxcp {pokemon}")
 	ERR_MOVES_COMMAND             = errors.New("Moves command needs to be formatted like this: !moves {pokemon}")
 	ERR_TYPES_COMMAND             = errors.New("Types command needs to be formatted like this: !type {pokemon}")
 	ERR_TYPECHART_COMMAND         = errors.New("Effect command needs to be formatted like this: !effect {pokemon}")
 	ERR_NORMAL_COMMAND            = errors.New("Normal command needs to be formatted liket his: !normal {pokemon}")
 	ERR_SHINY_COMMAND             = errors.New("Shiny command needs to be formatted like this: !shiny {pokemon}")
-	ERR_PREFIX_COMMAND            = errors.New("Set the prefix for your guild using !setprefix {prefix}. Max 2 characters.")
+	ERR_PREFIX_COMMAND            = errors.New("Set the prefix for your guild using !setprefix {prefix}. Max 5 characters. Use !prefix add {prefix} to add more than one.")
 	ERR_WELCOME_COMMAND           = errors.New("Set the welcome message for your server using !setwelcome {message}")
 	ERR_GOODBYE_COMMAND           = errors.New("Set the goodbye message for your server using !setgoodbye {message}")
 	ERR_NO_COMBINATIONS           = errors.New("No possible IV combinations for that CP")
@@ -48,6 +47,7 @@ var (
 	ERR_NO_TEAM    = errors.New("No team provided.")
 	ERR_NO_ROLE    = errors.New("No role provided.")
 	ERR_NOT_OWNER  = errors.New("Only the server owner can use that command :)")
+	ERR_GUILD_ONLY = errors.New("That command only works in a server, not in direct messages :)")
 
 	ERR_MISSING_ROLE = errors.New("Missing role.")
 	ERR_INVALID_ROLE = errors.New("Invalid role.")
@@ -58,9 +58,13 @@ var (
 type botResponse struct {
 	s       *discordgo.Session
 	m       *discordgo.MessageCreate
+	guild   *Guild
 	command string
 	fields  []string
 	err     error
+
+	previous []trackedReply
+	replies  []trackedReply
 }
 
 // Type Do is a placeholder for the function a command should execute
@@ -68,45 +72,95 @@ type Do func(b *botResponse) error
 
 // BotCommand is a representation of a command the bot can handle
 type BotCommand struct {
-	Name    string
-	Format  string
-	Info    string
-	Example []string
-	Print   bool
-	Aliases []string
+	Name     string
+	Format   string
+	Info     string
+	Example  []string
+	Print    bool
+	Aliases  []string
+	Suffixes []string
 	Do
 }
 
+// Commands that can't be used in direct messages
+var guildCommands = []string{
+	"team", "add", "setprefix", "prefix", "theme", "setwelcome", "setgoodbye", "addrole", "removerole",
+	"addcmd", "editcmd", "delcmd", "listcmd", "addalias", "delalias", "listalias",
+	"expire",
+}
+
 var cmdMap map[string]BotCommand
 var cmdList map[string]BotCommand
+var cmdSuffixes map[string]BotCommand
+var cmdSuffixList []string
 var botCommands = []BotCommand{
-	{"iv", "!iv [pokemon] [cp] [hp] {level|stardust} {adh}",
-		"Get possible IVs of a pokemon",
-		[]string{"!iv machamp 2526 143 33 a", "!iv pikachu 613 56 5000 ad", "!iv raichu 1703 98"}, true,
+	{"iv", "!iv [pokemon] [cp] [hp] {level|stardust} {adh} {'bb'}",
+		"Get possible IVs of a pokemon at levels up to 50, or 51 for best buddies",
+		[]string{"!iv machamp 2526 143 33 a", "!iv pikachu 613 56 5000 ad", "!iv raichu 1703 98", "!iv dragonite 4100 194 48.5 bb"}, true,
+		[]string{},
 		[]string{},
 		PrintIVToDiscord,
 	},
-	{"cp", "!cp [pokemon] [level] [attack iv] [defense iv] [stamina iv]",
+	{"cp", "!cp [pokemon] [level] [attack iv] [defense iv] [stamina iv] {'bb'}",
 		"Get CP of a pokemon at a specified level with specified IVs",
-		[]string{"!cp mewtwo 25 15 14 15"}, true,
+		[]string{"!cp mewtwo 25 15 14 15", "!cp mewtwo 50 15 15 15 bb"}, true,
+		[]string{},
 		[]string{},
 		PrintCPToDiscord,
 	},
 	{"maxcp", "!maxcp [pokemon]",
-		"Get maximum CP of a pokemon with perfect IVs at level 40",
+		"Get maximum CP of a pokemon with perfect IVs at levels 40, 50 and 51",
 		[]string{"!maxcp latios"}, true,
 		[]string{},
+		[]string{"maxcp"},
 		PrintMaxCPToDiscord,
 	},
+	{"cptable", "!cptable [pokemon] [attack iv] [defense iv] [stamina iv] {from level} {to level}",
+		"Get the CP and HP of a pokemon at every level, with the stardust and candy it takes to power it up",
+		[]string{"!cptable metagross 13 15 14", "!cptable metagross 13 15 14 30 50"}, true,
+		[]string{"cplevels"},
+		[]string{"cptable"},
+		PrintCPTableToDiscord,
+	},
+	{"powerup", "!powerup [pokemon] [level|cp] {attack iv} {defense iv} {stamina iv} [target level|target cp] {'shadow'|'purified'} {'lucky'}",
+		"Get the stardust and candy it takes to power up a pokemon to a level or CP",
+		[]string{"!powerup metagross 30 40", "!powerup metagross 2700 13 15 14 50", "!powerup metagross 30 13 15 14 3500 shadow lucky"}, true,
+		[]string{"powercost"},
+		[]string{},
+		PrintPowerUpToDiscord,
+	},
+	{"rank", "!rank [pokemon] [attack iv] [defense iv] [stamina iv] {great|ultra|master|little} {'bb'}",
+		"Get the PvP rank of an IV spread in a league, with the best level under the CP cap and the top spreads",
+		[]string{"!rank azumarill 0 15 15", "!rank registeel 0 14 15 ultra", "!rank medicham 15 15 15 great bb"}, true,
+		[]string{"pvprank"},
+		[]string{"rank"},
+		PrintRankToDiscord,
+	},
+	{"pvpchart", "!pvpchart [pokemon] {great|ultra|master|little} {floor} {'svg'}",
+		"Get a chart of the best IV spreads of a pokemon in a PvP league, optionally only ones from trades, raids or lucky trades",
+		[]string{"!pvpchart medicham great", "!pvpchart registeel ultra raid", "!pvpchart azumarill great lucky svg"}, true,
+		[]string{},
+		[]string{"pvpchart"},
+		PrintPvPChartToDiscord,
+	},
+	{"searchstring", "!searchstring [pokemon] [great|ultra|master|little|hundo|raid] {top}",
+		"Get in-game search strings that find the best IV spreads of a pokemon for a PvP league, hundos or raid attackers",
+		[]string{"!searchstring medicham great", "!searchstring registeel ultra 25", "!searchstring mewtwo raid"}, true,
+		[]string{"search"},
+		[]string{},
+		PrintSearchStringToDiscord,
+	},
 	{"raidiv", "!raidiv [pokemon] {cp}",
 		"Get possible IV combinations for specified raid pokemon with specified IV",
 		[]string{"!raidcp kyogre 2292", "!raidcp groudon"}, true,
-		[]string{"raidcp", "eggcp", "eggiv", "mewcp", "mewiv", "celebiiv", "celebicp", "jirachiiv", "jirachicp"},
+		[]string{"raidcp", "eggcp", "eggiv"},
+		[]string{"iv", "cp"},
 		PrintRaidCPToDiscord,
 	},
-	{"raidchart", "!raidchart [pokemon] {'full'}",
-		"Get a chart with possible stats for specified pokemon at raid level above 90%",
-		[]string{"!raidchart machamp", "!raidchart rayquaza full"}, true,
+	{"raidchart", "!raidchart [pokemon] {'full'} {'svg'}",
+		"Get a chart with possible stats for specified pokemon at raid level above 90%. React with ◀ ▶ to flip pages, or add 'full' for every page at once.",
+		[]string{"!raidchart machamp", "!raidchart rayquaza full", "!raidchart mewtwo svg"}, true,
+		[]string{},
 		[]string{},
 		PrintRaidChartToDiscord,
 	},
@@ -114,85 +168,183 @@ var botCommands = []BotCommand{
 		"Get a list of fast and charge moves for specified pokemon",
 		[]string{"!moves rayquaza"}, true,
 		[]string{},
+		[]string{"moves"},
 		PrintMovesToDiscord,
 	},
 	{"type", "!type [pokemon]",
 		"Get a list of types for a specified pokemon",
 		[]string{"!type rayquaza"}, true,
 		[]string{},
+		[]string{},
 		PrintTypeToDiscord,
 	},
 	{"effect", "!effect [pokemon|type]",
 		"Get a list of type relations a specified pokemon or type has",
 		[]string{"!effect pikachu", "!effect electric"}, true,
 		[]string{},
+		[]string{},
 		PrintTypeChartToDiscord,
 	},
 	{"luckydate", "!luckydate",
 		"Returns the date for pokemon to have been caught by for a higher change at luckies.",
 		[]string{"!luckydate"}, true,
 		[]string{},
+		[]string{},
 		PrintLuckyDateToDiscord,
 	},
 	{"shiny", "!shiny",
 		"Returns an image of the shiny version of a pokemon.",
 		[]string{"!shiny 3", "!shiny charmander"}, true,
 		[]string{},
+		[]string{"shiny"},
 		PrintShinyToDiscord,
 	},
 	{"normal", "!normal",
 		"Returns an image of the normal version of a pokemon.",
 		[]string{"!normal 3", "!normal charmander"}, true,
 		[]string{},
+		[]string{},
 		PrintNormalToDiscord,
 	},
 	{"wat", "!wat {command|'full'}",
 		"Get info about commands",
 		[]string{"!wat", "!wat full", "!wat raidcp"}, true,
 		[]string{"haynes-bot", "haynez-bot"},
+		[]string{},
 		PrintInfoToDiscord,
 	},
 	{"team", "!team {mystic|valor|instinct}",
 		"Get assigned to a team",
 		[]string{"!team mystic", "!team valor", "!team instinct"}, false,
 		[]string{},
+		[]string{},
 		AssignTeam,
 	},
 	{"add", "!add", "Add this guild to management",
-		[]string{}, false, []string{}, AddGuild,
+		[]string{}, false, []string{}, []string{}, AddGuild,
 	},
 	{"setprefix", "!setprefix {prefix string}", "Change bot prefix for server",
 		[]string{}, false, []string{},
+		[]string{},
 		SetBotPrefix,
 	},
+	{"prefix", "!prefix [add|remove|list|ignorecase] {prefix|on|off}", "Manage the bot prefixes for server",
+		[]string{"!prefix add hb!", "!prefix remove ?", "!prefix ignorecase on"}, false, []string{},
+		[]string{},
+		PrefixCommand,
+	},
+	{"expire", "!expire [duration|off|reset|commands] {'here'|on|off}", "Delete bot replies after a while",
+		[]string{"!expire 10m", "!expire 2m here", "!expire off here", "!expire commands on"}, false, []string{},
+		[]string{},
+		SetExpire,
+	},
+	{"theme", "!theme [primary|secondary|error|footer|name|icon|show|reset] {value}", "Change the colors and branding of bot replies for server",
+		[]string{"!theme primary #FF5733", "!theme footer Pokemon Go Raiders", "!theme icon https://example.com/logo.png", "!theme reset"}, false, []string{},
+		[]string{},
+		SetThemeCommand,
+	},
+	{"palette", "!palette {default|viridis|contrast|mono|custom} {stops} {'channel'|'server'}",
+		"Choose the colors for IV charts, for yourself or for the whole server",
+		[]string{"!palette viridis", "!palette custom 0:#440154,90:#21918c,100:#fde725", "!palette contrast server"}, true,
+		[]string{},
+		[]string{},
+		SetPaletteCommand,
+	},
+	{"images", "!images [on|off] {'channel'|'server'}",
+		"Choose between images and text for charts and tables, for yourself or for the whole server",
+		[]string{"!images off", "!images on server"}, true,
+		[]string{},
+		[]string{},
+		SetImagesCommand,
+	},
+	{"textonly", "!textonly [on|off]",
+		"Only get text replies, with charts and sprites described instead of shown",
+		[]string{"!textonly on", "!textonly off"}, true,
+		[]string{},
+		[]string{},
+		SetTextOnlyCommand,
+	},
+	{"prefs", "!prefs {get|set|reset} {name} {value} {'channel'|'server'}",
+		"Set your preferences for replies once: images, textonly, format, palette, league, timezone and language",
+		[]string{"!prefs", "!prefs set format compact", "!prefs set timezone Europe/Berlin", "!prefs set league ultra server", "!prefs reset all"}, true,
+		[]string{},
+		[]string{},
+		PrefsCommand,
+	},
+	{"language", "!language {language} {'channel'|'server'}",
+		"Choose the language of replies, for yourself or for the whole server",
+		[]string{"!language", "!language es", "!language pt server"}, true,
+		[]string{},
+		[]string{},
+		SetLanguageCommand,
+	},
 	{"setwelcome", "!setwelcome {message}", "Set welcome message for server",
 		[]string{}, false, []string{},
+		[]string{},
 		SetWelcome,
 	},
 	{"setgoodbye", "!setgoodbye {message}", "Set goodbye message for server",
 		[]string{}, false, []string{},
+		[]string{},
 		SetGoodbye,
 	},
 	{"addrole", "!addrole {role}", "Add role to your user",
 		[]string{"!addrole EX-raids"}, false, []string{},
+		[]string{},
 		AddRoleToUser,
 	},
 	{"removerole", "!removerole {role}", "Remove a role from your user",
 		[]string{"!removerole EX-raids"}, false, []string{},
+		[]string{},
 		RemoveRoleFromUser,
 	},
+	{"addcmd", "!addcmd [name] {'embed'} [response]", "Add a custom command for server",
+		[]string{"!addcmd rules Be nice to each other, {user}!", "!addcmd nests embed Check #nests"}, false, []string{},
+		[]string{},
+		AddCommand,
+	},
+	{"editcmd", "!editcmd [name] {'embed'} [response]", "Edit a custom command for server",
+		[]string{"!editcmd rules No spoofing in {guild}"}, false, []string{},
+		[]string{},
+		EditCommand,
+	},
+	{"delcmd", "!delcmd [name]", "Delete a custom command for server",
+		[]string{"!delcmd rules"}, false, []string{},
+		[]string{},
+		DeleteCommand,
+	},
+	{"listcmd", "!listcmd", "List the custom commands for this server",
+		[]string{"!listcmd"}, true, []string{},
+		[]string{},
+		ListCommands,
+	},
+	{"addalias", "!addalias [alias] [command] {options}", "Add an alias for a command for server",
+		[]string{"!addalias hundo raidiv", "!addalias stats iv"}, false, []string{},
+		[]string{},
+		AddAlias,
+	},
+	{"delalias", "!delalias [alias]", "Delete an alias for server",
+		[]string{"!delalias hundo"}, false, []string{},
+		[]string{},
+		DeleteAliasCommand,
+	},
+	{"listalias", "!listalias", "List the command aliases for this server",
+		[]string{"!listalias"}, true, []string{},
+		[]string{},
+		ListAliases,
+	},
 }
 
 // Formatting for info
 var INFO_FORMAT = "!cmd [required] [fields|options] {optional}"
 
 // PrintInfo prints the info for a discord command
-func (cmd *BotCommand) PrintInfo(prefix string) string {
+func (cmd *BotCommand) PrintInfo(prefix string, lang string) string {
 	examples := Example(strings.Replace(cmd.Format, "!", prefix, 1))
 	for _, ex := range cmd.Example {
 		examples += Example(strings.Replace(ex, "!", prefix, 1))
 	}
-	return fmt.Sprintln(cmd.Info, examples)
+	return fmt.Sprintln(Translate(lang, cmd.Info), examples)
 }
 
 //NewBotResponse creates an instance of a bot interaction
@@ -207,31 +359,48 @@ func (b *botResponse) GetCommand(prefix string) (cmd *BotCommand) {
 		return cmd
 	}
 
-	name := strings.ToLower(strings.Replace(b.fields[0], prefix, "", 1))
-	if len(name) > 2 && name[len(name)-2:len(name)] == "iv" {
-		pokemonName := name[0 : len(name)-2]
-		if _, err := pogo.GetPokemon(pokemonName); err == nil {
-			newfields := make([]string, len(b.fields)+1)
-			name = "raidiv"
-			for i, field := range b.fields {
-				if i == 0 {
-					newfields[i] = "raidiv"
-					continue
-				} else if i == 1 {
-					newfields[i] = pokemonName
-				}
-				newfields[i+1] = field
-			}
-			b.fields = newfields
+	name := strings.ToLower(strings.TrimPrefix(b.fields[0], prefix))
+	if b.guild != nil {
+		if target, ok := b.guild.GetAlias(name); ok {
+			name = target[0]
+			b.fields = append(target, b.fields[1:]...)
 		}
 	}
 
+	// Commands and their aliases come first, so a command that ends like a suffix isn't split
 	if c, ok := cmdMap[name]; ok {
 		return &c
-	} else {
-		b.err = ERR_COMMAND_UNRECOGNIZED
-		return cmd
 	}
+
+	if c, pokemonName, ok := getSuffixCommand(name); ok {
+		b.fields = append([]string{c.Name, pokemonName}, b.fields[1:]...)
+		return &c
+	}
+
+	if b.guild != nil {
+		if cc, ok := b.guild.GetCustomCommand(name); ok {
+			return customCommand(cc)
+		}
+	}
+
+	b.err = ERR_COMMAND_UNRECOGNIZED
+	return cmd
+}
+
+// getSuffixCommand splits a "<pokemon><suffix>" command like !groudoncp into the command and pokemon name
+func getSuffixCommand(name string) (BotCommand, string, bool) {
+	for _, suffix := range cmdSuffixList {
+		if len(name) <= len(suffix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+
+		pokemonName := strings.TrimSuffix(name, suffix)
+		if _, err := GetPokemon(pokemonName); err == nil {
+			return cmdSuffixes[suffix], pokemonName, true
+		}
+	}
+
+	return BotCommand{}, "", false
 }
 
 // Start starts the bot
@@ -252,6 +421,9 @@ func Start() {
 	BotID = u.ID
 
 	goBot.AddHandler(messageHandler)
+	goBot.AddHandler(messageUpdateHandler)
+	goBot.AddHandler(messageDeleteHandler)
+	goBot.AddHandler(paginationHandler)
 	goBot.AddHandler(welcomeHandler)
 	goBot.AddHandler(goodbyeHandler)
 	err = goBot.Open()
@@ -266,14 +438,20 @@ func Start() {
 		log.Println(err.Error())
 	}
 
+	expiring.Start(goBot, expiryFile())
+
+	if len(config.RaidBosses) > 0 {
+		log.Println("Warming raid charts...")
+		go warmRenderCache(config.RaidBosses)
+	}
+
 	err = goBot.UpdateStatus(0, "!wat")
 	if err != nil {
 		fmt.Println("Unable to update status: ", err.Error())
 	}
 
-	if UseImages {
-		//Start Image Server
-		http.Handle("/img", http.FileServer(http.Dir(ImageServer)))
+	if config.ImageAddr != "" {
+		startImageServer(config.ImageAddr)
 	}
 
 	log.Println("Bot is running!")
@@ -316,34 +494,62 @@ func goodbyeHandler(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
 }
 
 func messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
-	channel, err := s.Channel(m.ChannelID)
-	if err != nil {
-		return
-	}
+	handleCommand(s, m, nil)
+}
 
-	guild, ok := Guilds[channel.GuildID]
-	if !ok {
+// handleCommand runs the command in a message, reusing the previous replies if the message was edited
+func handleCommand(s *discordgo.Session, m *discordgo.MessageCreate, previous []trackedReply) {
+	if m.Author.ID == BotID {
 		return
 	}
 
-	guild.Update()
+	bot := NewBotResponse(s, m, nil)
+	bot.previous = previous
+	defer bot.finishReplies()
+
+	// Attempt to get the channel from the state
+	// If error, fall back to restapi
+	channel, err := s.State.Channel(m.ChannelID)
+	if err != nil {
+		channel, err = s.Channel(m.ChannelID)
+		if err != nil {
+			return
+		}
+	}
 
-	prefix := guild.Settings.BotPrefix
+	// Direct messages have no guild and use the default prefix
+	var guild *Guild
+	if channel.GuildID != "" {
+		g, ok := Guilds[channel.GuildID]
+		if !ok {
+			return
+		}
+		guild = g
+		guild.Update()
+	}
 
-	if !strings.HasPrefix(m.Content, prefix) {
+	content, ok := commandContent(guild, m.Content)
+	if !ok {
 		return
 	}
 
-	if m.Author.ID == BotID {
-		return
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		fields = []string{"wat"}
 	}
 
-	bot := NewBotResponse(s, m, strings.Fields(m.Content))
-	cmd := bot.GetCommand(prefix)
+	bot.fields = fields
+	bot.guild = guild
+	cmd := bot.GetCommand("")
 	if bot.err != nil {
 		return
 	}
 
+	if guild == nil && IsGuildCommand(cmd.Name) {
+		bot.PrintErrorToDiscord(&botError{ERR_GUILD_ONLY, cmd.Name})
+		return
+	}
+
 	err = cmd.Do(bot)
 	if err != nil {
 		bot.PrintErrorToDiscord(err)
@@ -353,6 +559,45 @@ func messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 }
 
+// commandContent strips the mention and prefix from a message, or returns false if it isn't a command.
+// A mention works as a prefix on its own, and can still be followed by the server's or the default prefix, like @haynesbot !iv
+func commandContent(guild *Guild, content string) (string, bool) {
+	content, mentioned := trimMention(content)
+
+	var prefix string
+	var ok bool
+	if guild != nil {
+		prefix, ok = guild.MatchPrefix(content)
+	}
+	if !ok && (guild == nil || mentioned) {
+		prefix, ok = matchPrefix(content, []string{config.BotPrefix}, false)
+	}
+	if !ok && !mentioned {
+		return "", false
+	}
+	return strings.TrimSpace(content[len(prefix):]), true
+}
+
+// trimMention strips a leading @haynesbot mention from a message, so it can be used as a prefix in any guild
+func trimMention(content string) (string, bool) {
+	for _, mention := range []string{"<@" + BotID + ">", "<@!" + BotID + ">"} {
+		if strings.HasPrefix(content, mention) {
+			return strings.TrimSpace(strings.TrimPrefix(content, mention)), true
+		}
+	}
+	return content, false
+}
+
+// IsGuildCommand returns true if a command only works inside a guild
+func IsGuildCommand(name string) bool {
+	for _, c := range guildCommands {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
 // AddGuild adds a guild to the guild management and checks for requirements
 func AddGuild(b *botResponse) error {
 	channel, err := b.s.Channel(b.m.ChannelID)
@@ -385,7 +630,7 @@ func AddGuild(b *botResponse) error {
 
 	guild.Manage(true)
 
-	b.PrintToDiscord("Guild management added!")
+	b.PrintToDiscord(b.T("Guild management added!"))
 
 	return nil
 }
@@ -411,7 +656,7 @@ func SetIVChannel(b *botResponse) error {
 		return &botError{ERR_NOT_OWNER, ""}
 	}
 
-	b.PrintToDiscord("Haynesbot IV Channel successfully changed.")
+	b.PrintToDiscord(b.T("Haynesbot IV Channel successfully changed."))
 
 	return nil
 }
@@ -439,12 +684,12 @@ func SetBotPrefix(b *botResponse) error {
 
 	if len(b.fields) > 1 {
 		prefix := b.fields[1]
-		if len(prefix) > 2 {
+		if !IsValidPrefix(prefix) {
 			return &botError{ERR_PREFIX_COMMAND, ""}
 		}
 		guild.SetPrefix(prefix)
 
-		b.PrintToDiscord("Haynesbot prefix successfully changed to " + prefix)
+		b.PrintToDiscord(b.T("Haynesbot prefix successfully changed to %s", prefix))
 	} else {
 		return &botError{ERR_PREFIX_COMMAND, ""}
 	}
@@ -503,7 +748,7 @@ func AssignTeam(b *botResponse) error {
 		return &botError{err, ""}
 	}
 
-	b.PrintToDiscord(fmt.Sprintf("You have been added to team %s!", team))
+	b.PrintToDiscord(b.T("You have been added to team %s!", team))
 
 	return nil
 }
@@ -549,7 +794,7 @@ func AddRoleToUser(b *botResponse) error {
 		return &botError{err, ""}
 	}
 
-	b.PrintToDiscord(fmt.Sprintf("You have been added to role %s!", role))
+	b.PrintToDiscord(b.T("You have been added to role %s!", role))
 
 	return nil
 }
@@ -595,7 +840,7 @@ func RemoveRoleFromUser(b *botResponse) error {
 		return &botError{err, ""}
 	}
 
-	b.PrintToDiscord(fmt.Sprintf("You have been removed from role %s!", role))
+	b.PrintToDiscord(b.T("You have been removed from role %s!", role))
 
 	return nil
 }
@@ -629,7 +874,7 @@ func SetWelcome(b *botResponse) error {
 		welcome := strings.Join(b.fields[1:], " ")
 		guild.SetWelcome(welcome)
 
-		b.PrintToDiscord("Welcome message set!")
+		b.PrintToDiscord(b.T("Welcome message set!"))
 	} else {
 		return &botError{ERR_WELCOME_COMMAND, ""}
 	}
@@ -665,7 +910,7 @@ func SetGoodbye(b *botResponse) error {
 	if len(b.fields) > 1 {
 		goodbye := strings.Join(b.fields[1:], " ")
 		guild.SetGoodbye(goodbye)
-		b.PrintToDiscord("Goodbye message set!")
+		b.PrintToDiscord(b.T("Goodbye message set!"))
 	} else {
 		return &botError{ERR_GOODBYE_COMMAND, ""}
 	}
@@ -677,18 +922,18 @@ func SetGoodbye(b *botResponse) error {
 func PrintNormalToDiscord(b *botResponse) error {
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
+	if p, err := GetPokemon(pokemonName); err == nil {
 		normal, err := p.GetNormal()
 		if err != nil {
-			return &botError{ERR_NO_IMAGE, p.Name}
+			return &botError{ERR_NO_IMAGE, b.PokemonName(p)}
 		}
 
 		f, err := os.Open(normal)
 		if err != nil {
-			return &botError{ERR_NO_IMAGE, p.Name}
+			return &botError{ERR_NO_IMAGE, b.PokemonName(p)}
 		}
 
-		b.SendImageToDiscord(fmt.Sprintf("%s.png", strings.Replace(strings.ToLower(p.Name), " ", "-", -1)), f)
+		b.SendImageToDiscord(fmt.Sprintf("%s.png", strings.Replace(strings.ToLower(p.Name), " ", "-", -1)), fmt.Sprintf("Sprite of %s", b.PokemonName(p)), f)
 	} else {
 		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
 	}
@@ -699,18 +944,18 @@ func PrintNormalToDiscord(b *botResponse) error {
 func PrintShinyToDiscord(b *botResponse) error {
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
+	if p, err := GetPokemon(pokemonName); err == nil {
 		shiny, err := p.GetShiny()
 		if err != nil {
-			return &botError{ERR_NO_IMAGE, p.Name}
+			return &botError{ERR_NO_IMAGE, b.PokemonName(p)}
 		}
 
 		f, err := os.Open(shiny)
 		if err != nil {
-			return &botError{ERR_NO_IMAGE, p.Name}
+			return &botError{ERR_NO_IMAGE, b.PokemonName(p)}
 		}
 
-		b.SendImageToDiscord(fmt.Sprintf("%s-shiny.png", strings.Replace(strings.ToLower(p.Name), " ", "-", -1)), f)
+		b.SendImageToDiscord(fmt.Sprintf("%s-shiny.png", strings.Replace(strings.ToLower(p.Name), " ", "-", -1)), fmt.Sprintf("Sprite of shiny %s", b.PokemonName(p)), f)
 	} else {
 		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
 	}
@@ -719,105 +964,116 @@ func PrintShinyToDiscord(b *botResponse) error {
 
 // PrintInfoToDiscord prints the bot info to discord
 func PrintInfoToDiscord(b *botResponse) error {
-	channel, err := b.s.Channel(b.m.ChannelID)
-	if err != nil {
-		return &botError{ERR_NO_CHANNEL, ""}
-	}
-
-	guild, ok := Guilds[channel.GuildID]
-	if !ok {
-		return &botError{ERR_NO_GUILD, ""}
+	prefix := config.BotPrefix
+	if b.guild != nil {
+		prefix = b.guild.Settings.BotPrefix
 	}
 
-	prefix := guild.Settings.BotPrefix
-
-	emb := NewEmbed().
+	emb := b.NewEmbed().
 		//SetTitle("Haynes Bot Commands").
-		SetColor(0x00ff00).
-		AddField("Commands", Example(strings.Replace(INFO_FORMAT, "!", prefix, 1)))
+		SetColorRole(ColorInfo).
+		AddField(b.T("Commands"), Example(strings.Replace(INFO_FORMAT, "!", prefix, 1)))
 
 	for _, cmd := range cmdList {
 		if !cmd.Print {
 			continue
 		}
+		aliases := ""
+		if guildAliases := b.guild.CommandAliases(cmd.Name); len(guildAliases) > 0 {
+			aliases = b.T("Server aliases: %s%s", prefix, strings.Join(guildAliases, ", "+prefix))
+		}
 		if len(b.fields) == 1 {
-			emb.AddField(prefix+cmd.Name, Example(strings.Replace(cmd.Format, "!", prefix, 1)))
+			emb.AddField(prefix+cmd.Name, Example(strings.Replace(cmd.Format, "!", prefix, 1))+aliases)
 			continue
 		} else if len(b.fields) > 1 {
 			if strings.ToLower(b.fields[1]) != cmd.Name && strings.ToLower(b.fields[1]) != "full" {
 				continue
 			}
 		}
-		emb.AddField(prefix+cmd.Name, cmd.PrintInfo(prefix))
+		emb.AddField(prefix+cmd.Name, cmd.PrintInfo(prefix, b.lang())+aliases)
 	}
+	b.addPrefixesField(emb, b.guild)
 	b.PrintEmbedToDiscord(emb.MessageEmbed)
 	return nil
 }
 
 // PrintIVToDiscord prints the IV data to discord
 func PrintIVToDiscord(b *botResponse) error {
-	if len(b.fields) < 3 {
+	fields, buddy := splitBestBuddy(b.fields)
+	if len(fields) < 4 {
 		return &botError{ERR_IV_COMMAND, ""}
 	}
 
-	pokemonName := strings.ToLower(b.fields[1])
+	pokemonName := strings.ToLower(fields[1])
 
-	cp, err := strconv.Atoi(b.fields[2])
+	cp, err := strconv.Atoi(fields[2])
 	if err != nil {
 		return &botError{ERR_IV_COMMAND, ""}
 	}
 
-	hp, err := strconv.Atoi(b.fields[3])
+	hp, err := strconv.Atoi(fields[3])
 	if err != nil {
 		return &botError{ERR_IV_COMMAND, ""}
 	}
 
-	level := 0.0
-	stardust := 0
-	if len(b.fields) > 4 {
-
-		val, err := strconv.ParseFloat(b.fields[4], 64)
+	levels := levelRange(MinLevel, MaxLevel)
+	if len(fields) > 4 {
+		level, stardust, err := ParseLevel(fields[4])
 		if err != nil {
-			return &botError{ERR_IV_COMMAND, ""}
+			return err
 		}
-		if val <= 40.0 {
-			level = val
+		if stardust > 0 {
+			levels = StardustLevels(stardust)
+		} else if buddy && level+BestBuddyBoost > MaxBuddyLevel {
+			return &botError{ERR_LEVEL_BEST_BUDDY, ""}
 		} else {
-			stardust = int(val)
+			levels = []float64{level}
 		}
 	}
 
 	bestvals := ""
-	if len(b.fields) > 5 {
-		if strings.Contains(b.fields[5], "a") {
+	if len(fields) > 5 {
+		if strings.Contains(fields[5], "a") {
 			bestvals += "a"
 		}
-		if strings.Contains(b.fields[5], "d") {
+		if strings.Contains(fields[5], "d") {
 			bestvals += "d"
 		}
-		if strings.Contains(b.fields[5], "h") || strings.Contains(b.fields[5], "s") {
+		if strings.Contains(fields[5], "h") || strings.Contains(fields[5], "s") {
 			bestvals += "s"
 		}
 	}
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
-		stats, ivChart := p.GetIV(cp, hp, level, stardust, bestvals)
-		if len(ivChart) == 0 {
-			return &botError{ERR_NO_COMBINATIONS, p.Name}
+	if p, err := GetPokemon(pokemonName); err == nil {
+		if p.Stats.BaseAttack == 0 {
+			return &botError{ERR_NO_STATS, b.PokemonName(p)}
+		}
+		stats := FindIVs(p.Stats, cp, hp, levels, bestvals, buddy)
+		ivChart := IVChart(stats)
+		title := fmt.Sprintf("%s - CP %d", b.PokemonName(p), cp)
+		if buddy {
+			title += " " + b.T("(best buddy)")
+		}
+		if len(stats) == 0 {
+			return &botError{ERR_NO_COMBINATIONS, b.PokemonName(p)}
+		} else if b.useImages() {
+			table := ChartTable(title, ivChart, b.palette(), b.lang()).SetPicture(PokemonPicture(p))
+			b.SendTableToDiscord(fmt.Sprintf("IV-%s-%d-%d", p.ID, cp, hp), table, FormatPNG)
 		} else {
-			emb := NewEmbed().
-				SetColor(0x9013FE).
-				AddField(fmt.Sprintf("CP: %d", cp), Example(ivChart)).
-				SetAuthor(p.Name, p.API.Sprites.Front)
-				//SetImage(p.API.Sprites.Front).MessageEmbed
-			if len(stats) > 30 {
-				emb.SetDescription("Full chart too long to display, displaying first 30 rows. \nAdd more data to limit results.")
+			rows := strings.Split(strings.TrimSpace(ivChart), "\n")
+			pages := []*discordgo.MessageEmbed{}
+			for _, page := range SplitRows(rows[1:], PageRows) {
+				emb := b.NewEmbed().
+					SetColorRole(ColorResult).
+					SetDescription(b.Tn("%d possible IV combination", "%d possible IV combinations", len(stats))).
+					AddField(title, Example(rows[0]+"\n"+strings.Join(page, "\n"))).
+					SetAuthor(b.PokemonName(p), p.API.Sprites.Front).MessageEmbed
+				pages = append(pages, emb)
 			}
-			b.PrintEmbedToDiscord(emb.MessageEmbed)
-			//b.PrintToDiscord(ivChart)
+			b.PrintPagesToDiscord(pages)
 		}
 	} else {
-		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
+		return &botError{ERR_POKEMON_UNRECOGNIZED, fields[1]}
 	}
 
 	return nil
@@ -825,29 +1081,44 @@ func PrintIVToDiscord(b *botResponse) error {
 
 // PrintCPToDiscord prints CP info based on input to discord
 func PrintCPToDiscord(b *botResponse) error {
-	if len(b.fields) < 6 {
+	fields, buddy := splitBestBuddy(b.fields)
+	if len(fields) < 6 {
 		return &botError{ERR_CP_COMMAND, ""}
 	}
 
-	pokemonName := strings.ToLower(b.fields[1])
+	pokemonName := strings.ToLower(fields[1])
 
-	level, err := strconv.ParseFloat(b.fields[2], 64)
-	ivA, err := strconv.Atoi(b.fields[3])
-	ivD, err := strconv.Atoi(b.fields[4])
-	ivS, err := strconv.Atoi(b.fields[5])
-	if err != nil {
+	level, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(fields[2]), "l"), 64)
+	if err != nil || !ValidLevel(level) {
+		return &botError{ERR_LEVEL_INVALID, fields[2]}
+	}
+	if buddy {
+		if level+BestBuddyBoost > MaxBuddyLevel {
+			return &botError{ERR_LEVEL_BEST_BUDDY, ""}
+		}
+		level += BestBuddyBoost
+	}
+
+	ivA, ivD, ivS, ok := parseIVs(fields[3:6])
+	if !ok {
 		return &botError{ERR_CP_COMMAND, ""}
 	}
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
-		cp := p.GetCP(level, ivA, ivD, ivS)
-		emb := NewEmbed().
-			SetColor(0x9013FE).
-			AddField(p.Name, fmt.Sprintf("CP at level %v with IVs %d/%d/%d: %d", level, ivA, ivD, ivS, cp)).
-			SetThumbnail(p.API.Sprites.Front).MessageEmbed
-		b.PrintEmbedToDiscord(emb)
+	if p, err := GetPokemon(pokemonName); err == nil {
+		if p.Stats.BaseAttack == 0 {
+			return &botError{ERR_NO_STATS, b.PokemonName(p)}
+		}
+		cp := CalcCP(p.Stats, level, ivA, ivD, ivS)
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			AddField(b.PokemonName(p), b.T("CP at level %v with IVs %d/%d/%d: %d", level, ivA, ivD, ivS, cp)).
+			SetThumbnail(p.API.Sprites.Front)
+		if buddy {
+			emb.SetFooter(b.T("Best buddy, boosted to level %v", level))
+		}
+		b.PrintEmbedToDiscord(emb.MessageEmbed)
 	} else {
-		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
+		return &botError{ERR_POKEMON_UNRECOGNIZED, fields[1]}
 	}
 
 	return nil
@@ -861,14 +1132,18 @@ func PrintMaxCPToDiscord(b *botResponse) error {
 
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
-		maxcp := p.GetMaxCP()
-		if maxcp == 0 {
-			return &botError{ERR_NO_STATS, p.Name}
+	if p, err := GetPokemon(pokemonName); err == nil {
+		if p.Stats.BaseAttack == 0 {
+			return &botError{ERR_NO_STATS, b.PokemonName(p)}
+		}
+		lines := []string{}
+		for _, level := range []float64{40, MaxLevel, MaxBuddyLevel} {
+			lines = append(lines, b.T("Max CP at level %v: %d", level, CalcCP(p.Stats, level, 15, 15, 15)))
 		}
-		emb := NewEmbed().
-			SetColor(0x9013FE).
-			AddField(p.Name, fmt.Sprintf("Max CP: %v", maxcp)).
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			AddField(b.PokemonName(p), strings.Join(lines, "\n")).
+			SetFooter(b.T("Level 51 is a level 50 best buddy")).
 			SetThumbnail(p.API.Sprites.Front).MessageEmbed
 		b.PrintEmbedToDiscord(emb)
 	} else {
@@ -886,54 +1161,36 @@ func PrintRaidChartToDiscord(b *botResponse) error {
 
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
+	if p, err := GetPokemon(pokemonName); err == nil {
 		ivList, chart := p.GetRaidCPChart()
-		if UseImages {
-			imgName := fmt.Sprintf("RAIDCHART-%s.png", p.ID)
-			if ImageExists(imgName) {
-				f, err := os.Open(ImageServer + "/" + imgName)
-				if err != nil {
-					fmt.Println(err.Error())
-				}
-				b.SendImageToDiscord(imgName, f)
+		format := FormatPNG
+		full := false
+		for _, arg := range b.fields[2:] {
+			if arg = strings.ToLower(arg); arg == "full" {
+				full = true
+			} else if IsTableFormat(arg) {
+				format = arg
 			} else {
-				fmt.Println("Getting table")
-				GetTable(p, ivList, imgName)
-
-				f, err := os.Open(ImageServer + "/" + imgName)
-				if err != nil {
-					fmt.Println(err.Error())
-				}
-
-				b.SendImageToDiscord(imgName, f)
+				return &botError{ERR_RAIDCHART_COMMAND, ""}
 			}
-
+		}
+		if b.useImages() || (format == FormatSVG && !b.textOnly()) {
+			b.SendTableToDiscord("RAIDCHART-"+p.ID, RaidChartTable(p, ivList, b.palette(), b.lang()), format)
 		} else {
-			rows := strings.Split(chart, "\n")
-			emb := NewEmbed().
-				SetColor(0x9013FE).
-				AddField("Raid Chart", Example(strings.Join(rows[:40], "\n")))
-
-			if len(b.fields) > 2 {
-				if strings.ToLower(b.fields[2]) == "full" {
-					rowCount := len(rows)
-					st, en := 41, 80
-					for {
-						if en > rowCount {
-							en = rowCount
-						}
-						emb.AddField("Continued", Example(strings.Join(rows[st:en], "\n")))
-
-						if en == rowCount || en > 200 {
-							break
-						}
-						st += 40
-						en += 40
-					}
-				}
+			rows := strings.Split(strings.TrimSpace(chart), "\n")
+			pages := []*discordgo.MessageEmbed{}
+			for _, page := range SplitRows(rows, PageRows) {
+				emb := b.NewEmbed().
+					SetColorRole(ColorResult).
+					AddField(b.T("Raid Chart"), Example(strings.Join(page, "\n"))).
+					SetAuthor(b.PokemonName(p), p.API.Sprites.Front).MessageEmbed
+				pages = append(pages, emb)
+			}
+			if full {
+				b.PrintEveryPageToDiscord(pages)
+			} else {
+				b.PrintPagesToDiscord(pages)
 			}
-			emb.SetAuthor(p.Name, p.API.Sprites.Front)
-			b.PrintEmbedToDiscord(emb.MessageEmbed)
 		}
 	} else {
 		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
@@ -950,11 +1207,11 @@ func PrintRaidCPToDiscord(b *botResponse) error {
 
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
+	if p, err := GetPokemon(pokemonName); err == nil {
 		if len(b.fields) == 2 {
-			emb := NewEmbed().
-				SetColor(0x9013FE).
-				AddField(p.Name+" Raid CP", p.GetRaidCPRange()).
+			emb := b.NewEmbed().
+				SetColorRole(ColorResult).
+				AddField(b.T("%s Raid CP", b.PokemonName(p)), p.GetRaidCPRange()).
 				SetThumbnail(p.API.Sprites.Front).MessageEmbed
 			b.PrintEmbedToDiscord(emb)
 		} else {
@@ -962,34 +1219,18 @@ func PrintRaidCPToDiscord(b *botResponse) error {
 			if err != nil {
 				return &botError{ERR_RAIDCP_COMMAND, ""}
 			}
-			//imgName := fmt.Sprintf("RAID-%s-%d.png", p.Name, cp)
-			_, ivChart := p.GetRaidIV(cp)
-			/*if UseImages {
-				//imgName := fmt.Sprintf("RAID-%s-%d", p.Name, cp)
-				//imgName := "draw.png"
-				if ImageExists(imgName) {
-					f, err := os.Open(ImageServer + "/" + imgName)
-					if err != nil {
-						fmt.Println(err.Error())
-					}
-					b.SendImageToDiscord(imgName, f)
-				} else {
-					file := GetTable(ivList, imgName)
-
-					b.SendImageToDiscord(imgName, file)
-				}
-			} else {*/
+			ivList, ivChart := p.GetRaidIV(cp)
 			if len(ivChart) == 0 {
-				return &botError{ERR_NO_COMBINATIONS, p.Name}
+				return &botError{ERR_NO_COMBINATIONS, b.PokemonName(p)}
+			} else if b.useImages() {
+				b.SendTableToDiscord(fmt.Sprintf("RAID-%s-%d", p.ID, cp), RaidIVTable(p, cp, ivList, b.palette(), b.lang()), FormatPNG)
 			} else {
-				emb := NewEmbed().
-					SetColor(0x9013FE).
-					AddField(fmt.Sprintf("CP: %d", cp), Example(ivChart)).
-					SetAuthor(p.Name, p.API.Sprites.Front).MessageEmbed
-				//SetImage(p.API.Sprites.Front).MessageEmbed
+				emb := b.NewEmbed().
+					SetColorRole(ColorResult).
+					AddField(b.T("CP: %d", cp), Example(ivChart)).
+					SetAuthor(b.PokemonName(p), p.API.Sprites.Front).MessageEmbed
 				b.PrintEmbedToDiscord(emb)
 			}
-			//}
 		}
 	} else {
 		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
@@ -1006,12 +1247,18 @@ func PrintMovesToDiscord(b *botResponse) error {
 
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
-		emb := NewEmbed().
-			SetTitle(fmt.Sprintf("Moves for %s", p.Name)).
-			SetColor(0x0B9EFF).
-			AddField("Fast", p.Moves.Fast.Print()).
-			AddField("Charge", p.Moves.Charge.Print()).
+	if p, err := GetPokemon(pokemonName); err == nil {
+		if b.useImages() {
+			table := TextTable(b.T("Moves for %s", b.PokemonName(p)), []string{b.T("Fast"), b.T("Charge")},
+				[]string{b.Names(KindMove, p.Moves.Fast.Print()), b.Names(KindMove, p.Moves.Charge.Print())})
+			b.SendTableToDiscord("MOVES-"+p.ID, table, FormatPNG)
+			return nil
+		}
+		emb := b.NewEmbed().
+			SetTitle(b.T("Moves for %s", b.PokemonName(p))).
+			SetColorRole(ColorInfo).
+			AddField(b.T("Fast"), b.Names(KindMove, p.Moves.Fast.Print())).
+			AddField(b.T("Charge"), b.Names(KindMove, p.Moves.Charge.Print())).
 			SetThumbnail(p.API.Sprites.Front).MessageEmbed
 		b.PrintEmbedToDiscord(emb)
 	} else {
@@ -1029,10 +1276,10 @@ func PrintTypeToDiscord(b *botResponse) error {
 
 	pokemonName := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(pokemonName); err == nil {
-		emb := NewEmbed().
-			SetColor(0x9013FE).
-			AddField(fmt.Sprintf("Type for %s", p.Name), p.Types.Print()).
+	if p, err := GetPokemon(pokemonName); err == nil {
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			AddField(b.T("Type for %s", b.PokemonName(p)), b.Names(KindType, p.Types.Print())).
 			SetThumbnail(p.API.Sprites.Front).MessageEmbed
 		b.PrintEmbedToDiscord(emb)
 	} else {
@@ -1044,10 +1291,10 @@ func PrintTypeToDiscord(b *botResponse) error {
 
 // PrintLuckyDateToDiscord prints the lucky date to discord
 func PrintLuckyDateToDiscord(b *botResponse) error {
-	now := time.Now()
+	now := time.Now().In(b.location())
 	luckydate := now.AddDate(0, 0, -780)
-	msg := fmt.Sprintf("Any Pokémon older than **%s** has the highest chance to become lucky.", luckydate.Format("01/02/2006"))
-	_, _ = b.s.ChannelMessageSend(b.m.ChannelID, msg)
+	msg := b.T("Any Pokémon older than **%s** has the highest chance to become lucky.", luckydate.Format(b.T("01/02/2006")))
+	b.PrintToDiscord(msg)
 
 	return nil
 }
@@ -1060,24 +1307,37 @@ func PrintTypeChartToDiscord(b *botResponse) error {
 
 	typeValue := strings.ToLower(b.fields[1])
 
-	if p, err := pogo.GetPokemon(typeValue); err == nil {
-		emb := NewEmbed().
-			SetColor(0x9013FE).
-			SetTitle(fmt.Sprintf("Type Effects for %s", p.Name)).
-			AddField("Super Effective", p.SuperEffective.Print()).
-			AddField("Not Effective", p.NotEffective.Print()).
-			AddField("Weaknesses", p.Weakness.Print()).
-			AddField("Resistance", p.Resistance.Print()).
+	headers := []string{b.T("Super Effective"), b.T("Not Effective"), b.T("Weaknesses"), b.T("Resistance")}
+	if p, err := GetPokemon(typeValue); err == nil {
+		if b.useImages() {
+			table := TextTable(b.T("Type Effects for %s", b.PokemonName(p)), headers,
+				[]string{b.Names(KindType, p.SuperEffective.Print()), b.Names(KindType, p.NotEffective.Print()), b.Names(KindType, p.Weakness.Print()), b.Names(KindType, p.Resistance.Print())})
+			b.SendTableToDiscord("EFFECT-"+p.ID, table, FormatPNG)
+			return nil
+		}
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			SetTitle(b.T("Type Effects for %s", b.PokemonName(p))).
+			AddField(headers[0], b.Names(KindType, p.SuperEffective.Print())).
+			AddField(headers[1], b.Names(KindType, p.NotEffective.Print())).
+			AddField(headers[2], b.Names(KindType, p.Weakness.Print())).
+			AddField(headers[3], b.Names(KindType, p.Resistance.Print())).
 			SetThumbnail(p.API.Sprites.Front).MessageEmbed
 		b.PrintEmbedToDiscord(emb)
-	} else if t, err := pogo.GetType(typeValue); err == nil {
-		emb := NewEmbed().
-			SetColor(0x9013FE).
-			SetTitle(fmt.Sprintf("Type Effects for %s", t.Name)).
-			AddField("Super Effective", t.SuperEffective.Print()).
-			AddField("Not Effective", t.NotEffective.Print()).
-			AddField("Weaknesses", t.Weakness.Print()).
-			AddField("Resistance", t.Resistance.Print()).
+	} else if t, err := GetType(typeValue); err == nil {
+		if b.useImages() {
+			table := TextTable(b.T("Type Effects for %s", b.Names(KindType, t.Name)), headers,
+				[]string{b.Names(KindType, t.SuperEffective.Print()), b.Names(KindType, t.NotEffective.Print()), b.Names(KindType, t.Weakness.Print()), b.Names(KindType, t.Resistance.Print())})
+			b.SendTableToDiscord("EFFECT-"+t.Name, table, FormatPNG)
+			return nil
+		}
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			SetTitle(b.T("Type Effects for %s", b.Names(KindType, t.Name))).
+			AddField(headers[0], b.Names(KindType, t.SuperEffective.Print())).
+			AddField(headers[1], b.Names(KindType, t.NotEffective.Print())).
+			AddField(headers[2], b.Names(KindType, t.Weakness.Print())).
+			AddField(headers[3], b.Names(KindType, t.Resistance.Print())).
 			SetThumbnail(t.Thumbnail).MessageEmbed
 		b.PrintEmbedToDiscord(emb)
 	} else {
@@ -1086,34 +1346,89 @@ func PrintTypeChartToDiscord(b *botResponse) error {
 	return nil
 }
 
-// SendImageToDiscord sends an image as a file attachment to discord
-func (b *botResponse) SendImageToDiscord(fileName string, r io.Reader) {
-	_, _ = b.s.ChannelFileSend(b.m.ChannelID, fileName, r)
+// SendImageToDiscord sends an image as a file attachment to discord, with alt text as the message.
+// Users who only want text get just the alt text.
+func (b *botResponse) SendImageToDiscord(fileName string, alt string, r io.Reader) {
+	if b.textOnly() {
+		b.PrintToDiscord(alt)
+		return
+	}
+
+	// Attachments can't be edited, so an edited command always gets a new image
+	b.reuseReply(replyImage)
+	m, _ := b.s.ChannelFileSendWithMessage(b.m.ChannelID, truncate(alt, MessageLimit), fileName, r)
+	b.track(m, replyImage)
 	return
 }
 
-// PrintToDiscord prints the message string to discord
+// PrintToDiscord prints the message string to discord, split into more messages if it is too long
 func (b *botResponse) PrintToDiscord(msg string) {
-	_, _ = b.s.ChannelMessageSend(b.m.ChannelID, msg)
+	for _, text := range SplitText(msg, MessageLimit) {
+		b.sendText(text)
+	}
 	return
 }
 
-// Print embed to discord prints an embed to discord
+// sendText sends a message to discord, or edits the previous reply if the command was edited
+func (b *botResponse) sendText(msg string) *discordgo.Message {
+	if old, ok := b.reuseReply(replyText); ok {
+		if m, err := b.s.ChannelMessageEdit(old.ChannelID, old.MessageID, msg); err == nil {
+			b.track(m, replyText)
+			return m
+		}
+	}
+	m, _ := b.s.ChannelMessageSend(b.m.ChannelID, msg)
+	b.track(m, replyText)
+	return m
+}
+
+// Print embed to discord prints an embed to discord, split into more embeds if it is too long
 func (b *botResponse) PrintEmbedToDiscord(e *discordgo.MessageEmbed) {
-	_, _ = b.s.ChannelMessageSendEmbed(b.m.ChannelID, e)
+	b.theme().Apply(e)
+	for _, emb := range (&Embed{MessageEmbed: e}).Split() {
+		b.sendEmbed(emb)
+	}
+}
+
+// sendEmbed sends an embed to discord, or edits the previous reply if the command was edited
+func (b *botResponse) sendEmbed(e *discordgo.MessageEmbed) *discordgo.Message {
+	if old, ok := b.reuseReply(replyEmbed); ok {
+		if m, err := b.s.ChannelMessageEditEmbed(old.ChannelID, old.MessageID, e); err == nil {
+			b.track(m, replyEmbed)
+			return m
+		}
+	}
+	m, _ := b.s.ChannelMessageSendEmbed(b.m.ChannelID, e)
+	b.track(m, replyEmbed)
+	return m
 }
 
-// PrintErrorToDiscord prints the error to discord
+// PrintErrorToDiscord prints the error to discord in the warning color of the theme
 func (b *botResponse) PrintErrorToDiscord(err error) {
+	if emb := b.errorEmbed(err); emb != nil {
+		b.PrintEmbedToDiscord(emb)
+	}
+}
+
+// errorEmbed creates the embed for an error, or nil if the error is silent
+func (b *botResponse) errorEmbed(err error) *discordgo.MessageEmbed {
+	var msg string
 	if berr, ok := err.(*botError); ok {
-		if berr.Error() == "" {
-			return
+		// Missing guilds are only worth a reply in direct messages, where the user asked for something server wide
+		if berr.err == ERR_NO_GUILD && b.guild == nil {
+			berr = &botError{ERR_GUILD_ONLY, ""}
 		}
-		_, _ = b.s.ChannelMessageSend(b.m.ChannelID, berr.Error())
+		msg = berr.Localize(b.lang())
 	} else {
-		_, _ = b.s.ChannelMessageSend(b.m.ChannelID, err.Error())
+		msg = b.T(err.Error())
 	}
-	return
+	if msg == "" {
+		return nil
+	}
+
+	return b.NewEmbed().
+		SetColorRole(ColorWarning).
+		SetDescription(msg).MessageEmbed
 }
 
 type botError struct {
@@ -1125,6 +1440,13 @@ var silentErrors = []error{ERR_NO_CHANNEL, ERR_NOT_MANAGED, ERR_NO_GUILD}
 
 // Error formats the error message for printing
 func (e *botError) Error() string {
+	return e.Localize(DefaultLanguage)
+}
+
+// Localize formats the error message for printing in a language
+func (e *botError) Localize(lang string) string {
+	T := func(msg string) string { return Translate(lang, msg) }
+
 	for _, se := range silentErrors {
 		if e.err == se {
 			log.Println(e.err)
@@ -1133,21 +1455,47 @@ func (e *botError) Error() string {
 	}
 
 	if e.err == ERR_POKEMON_UNRECOGNIZED && e.value != "" {
-		return fmt.Sprintf("Pokemon unrecognized: %s", e.value)
+		return fmt.Sprintf(T("Pokemon unrecognized: %s"), e.value)
 	} else if e.err == ERR_POKEMON_TYPE_UNRECOGNIZED && e.value != "" {
-		return fmt.Sprintf("Pokemon/type unrecognized: %s", e.value)
+		return fmt.Sprintf(T("Pokemon/type unrecognized: %s"), e.value)
 	} else if e.err == ERR_NO_COMBINATIONS && e.value != "" {
-		return fmt.Sprintf("No possible IV combinations for that CP for %s", e.value)
+		return fmt.Sprintf(T("No possible IV combinations for that CP for %s"), e.value)
 	} else if e.err == ERR_NO_STATS && e.value != "" {
-		return fmt.Sprintf("No stats available for %s in the Pokemon Go Master file yet :(", e.value)
+		return fmt.Sprintf(T("No stats available for %s in the Pokemon Go Master file yet :("), e.value)
 	} else if e.err == ERR_MISSING_ROLE && e.value != "" {
-		return fmt.Sprintf("Missing role: %s", e.value)
+		return fmt.Sprintf(T("Missing role: %s"), e.value)
 	} else if e.err == ERR_INVALID_ROLE && e.value != "" {
-		return fmt.Sprintf("Invalid role: %s", e.value)
+		return fmt.Sprintf(T("Invalid role: %s"), e.value)
 	} else if e.err == ERR_NO_IMAGE && e.value != "" {
-		return fmt.Sprintf("No image found for: %s", e.value)
-	}
-	return e.err.Error()
+		return fmt.Sprintf(T("No image found for: %s"), e.value)
+	} else if e.err == ERR_GUILD_ONLY && e.value != "" {
+		return fmt.Sprintf(T("Sorry, %s only works in a server, not in direct messages :)"), e.value)
+	} else if e.err == ERR_PREFIX_EXISTS && e.value != "" {
+		return fmt.Sprintf(T("Prefix %s is already set."), e.value)
+	} else if e.err == ERR_PREFIX_MISSING && e.value != "" {
+		return fmt.Sprintf(T("Prefix %s isn't set."), e.value)
+	} else if e.err == ERR_CMD_BUILTIN && e.value != "" {
+		return fmt.Sprintf(T("%s is already a haynesbot command."), e.value)
+	} else if e.err == ERR_CMD_EXISTS && e.value != "" {
+		return fmt.Sprintf(T("Command %s already exists. Change it with !editcmd"), e.value)
+	} else if e.err == ERR_PREF_UNKNOWN && e.value != "" {
+		return fmt.Sprintf(T("Unknown preference: %s. Use !prefs to see them all."), e.value)
+	} else if e.err == ERR_PREF_INVALID && e.value != "" {
+		pref, _ := GetPreference(e.value)
+		if len(pref.Values) > 0 {
+			return fmt.Sprintf(T("%s needs to be one of: %s"), pref.Name, strings.Join(pref.Values, ", "))
+		}
+		return fmt.Sprintf(T("Invalid %s. %s"), pref.Name, T(pref.Info))
+	} else if e.err == ERR_PREF_USER_ONLY && e.value != "" {
+		return fmt.Sprintf(T("%s can only be set for yourself."), e.value)
+	} else if e.err == ERR_LEVEL_INVALID && e.value != "" {
+		return fmt.Sprintf(T("%s isn't a level from 1 to 51 or a stardust cost like 5000 or 10000"), e.value)
+	} else if e.err == ERR_POWERUP_CP && e.value != "" {
+		return fmt.Sprintf(T("No level gives CP %s with those IVs"), e.value)
+	} else if e.err == ERR_CMD_MISSING && e.value != "" {
+		return fmt.Sprintf(T("Command doesn't exist: %s"), e.value)
+	}
+	return T(e.err.Error())
 }
 
 // ImageExists checks if an image exists in the image server folder
@@ -1161,6 +1509,7 @@ func ImageExists(name string) bool {
 func init() {
 	cmdList = make(map[string]BotCommand)
 	cmdMap = make(map[string]BotCommand)
+	cmdSuffixes = make(map[string]BotCommand)
 	for _, cmd := range botCommands {
 		cmdList[cmd.Name] = cmd
 		cmdMap[cmd.Name] = cmd
@@ -1169,5 +1518,14 @@ func init() {
 				cmdMap[alias] = cmd
 			}
 		}
+		for _, suffix := range cmd.Suffixes {
+			cmdSuffixes[suffix] = cmd
+			cmdSuffixList = append(cmdSuffixList, suffix)
+		}
 	}
+
+	// Check longer suffixes first so !groudonmaxcp isn't read as "groudonmax" + "cp"
+	sort.Slice(cmdSuffixList, func(i, j int) bool {
+		return len(cmdSuffixList[i]) > len(cmdSuffixList[j])
+	})
 }
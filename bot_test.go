@@ -0,0 +1,79 @@
+package haynesbot
+
+import "testing"
+
+func TestSuffixOrder(t *testing.T) {
+	seen := map[string]int{}
+	for i, suffix := range cmdSuffixList {
+		seen[suffix] = i
+	}
+
+	if seen["maxcp"] > seen["cp"] {
+		t.Errorf("maxcp suffix should be checked before cp: %v", cmdSuffixList)
+	}
+
+	if cmdSuffixes["cp"].Name != "raidiv" || cmdSuffixes["maxcp"].Name != "maxcp" {
+		t.Errorf("unexpected suffix commands: cp=%s maxcp=%s", cmdSuffixes["cp"].Name, cmdSuffixes["maxcp"].Name)
+	}
+}
+
+func TestTrimMention(t *testing.T) {
+	BotID = "402854185072328714"
+
+	tests := []struct {
+		content   string
+		want      string
+		mentioned bool
+	}{
+		{"<@402854185072328714> iv machamp 2526 143 33", "iv machamp 2526 143 33", true},
+		{"<@!402854185072328714>   !wat", "!wat", true},
+		{"!wat <@402854185072328714>", "!wat <@402854185072328714>", false},
+	}
+
+	for _, tt := range tests {
+		got, mentioned := trimMention(tt.content)
+		if got != tt.want || mentioned != tt.mentioned {
+			t.Errorf("trimMention(%q) = %q, %v, want %q, %v", tt.content, got, mentioned, tt.want, tt.mentioned)
+		}
+	}
+}
+
+func TestCommandContent(t *testing.T) {
+	BotID = "402854185072328714"
+	oldConfig := config
+	config = &configStruct{BotPrefix: "!"}
+	defer func() { config = oldConfig }()
+
+	guild := &Guild{Settings: GuildSetting{BotPrefix: "$"}}
+
+	tests := []struct {
+		guild   *Guild
+		content string
+		want    string
+		ok      bool
+	}{
+		{guild, "$iv machamp 2526 143 33", "iv machamp 2526 143 33", true},
+		{guild, "!iv machamp 2526 143 33", "", false},
+		{guild, "<@402854185072328714> iv machamp", "iv machamp", true},
+		{guild, "<@402854185072328714> $iv machamp", "iv machamp", true},
+		{guild, "<@!402854185072328714> !iv machamp", "iv machamp", true},
+		{nil, "!wat", "wat", true},
+		{nil, "<@402854185072328714> !wat", "wat", true},
+		{nil, "wat", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := commandContent(tt.guild, tt.content)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("commandContent(%q) = %q, %v, want %q, %v", tt.content, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGetCommandBeforeSuffix(t *testing.T) {
+	// raidcp ends in the cp suffix, but it's an alias of raidiv
+	b := &botResponse{fields: []string{"!raidcp", "mewtwo"}}
+	if cmd := b.GetCommand("!"); cmd == nil || cmd.Name != "raidiv" || len(b.fields) != 2 {
+		t.Errorf("!raidcp resolved to %v with fields %v, want raidiv", cmd, b.fields)
+	}
+}
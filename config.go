@@ -29,8 +29,24 @@ type configStruct struct {
 	TestPrefix    string `json:"TestPrefix"`
 	Images        bool   `json:"Images"`
 	ImageServer   string `json:"ImageServer"`
+	ImageAddr     string `json:"ImageAddr"`
 	GuildFile     string `json:"GuildSettings"`
 	TestGuildFile string `json:"TestGuildSettings"`
+	ExpiryFile    string `json:"ExpiryFile"`
+	UserFile      string `json:"UserSettings"`
+	TestUserFile  string `json:"TestUserSettings"`
+
+	RenderCacheSize int    `json:"RenderCacheSize"`
+	RenderCacheDir  string `json:"RenderCacheDir"`
+	RenderDiskSize  int    `json:"RenderCacheDiskSize"`
+	RenderWorkers   int    `json:"RenderWorkers"`
+	RenderQueue     int    `json:"RenderQueue"`
+
+	RaidBosses []string `json:"RaidBosses"`
+
+	Prefs       map[string]string `json:"Preferences"`
+	LocaleDir   string            `json:"Locales"`
+	PokedexFile string            `json:"Pokedex"`
 }
 
 // ReadConfig reads the config file and initializes values using those configs
@@ -60,16 +76,34 @@ func ReadConfig() error {
 		config.Token = config.TestToken
 		config.BotPrefix = config.TestPrefix
 		config.GuildFile = config.TestGuildFile
+		config.UserFile = config.TestUserFile
 	}
 
 	log.Println("Guild file: ", config.GuildFile)
 	readGuildSettings(config.GuildFile)
+	readUserSettings(userFile())
 
 	TestToken = config.TestToken
 	Token = config.Token
 	BotPrefix = config.BotPrefix
 	UseImages = config.Images
 	ImageServer = config.ImageServer
+	initRenderCache()
+	initRenderPool()
+
+	if config.LocaleDir == "" {
+		config.LocaleDir = path.Join(path.Dir(filename), "../locales")
+	}
+	if err := LoadCatalogs(config.LocaleDir); err != nil {
+		log.Println("Unable to read translations:", err)
+	}
+
+	if config.PokedexFile == "" {
+		config.PokedexFile = path.Join(path.Dir(filename), "pokemonNames.csv")
+	}
+	if err := LoadPokedex(config.PokedexFile); err != nil {
+		log.Println("Unable to read pokedex numbers:", err)
+	}
 
 	return nil
 }
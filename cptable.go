@@ -0,0 +1,108 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/haynesherway/pogo"
+)
+
+// CP table errors
+var (
+	ERR_CPTABLE_COMMAND = errors.New("CP table command needs to be formatted like this: !cptable {pokemon} {attack iv} {defense iv} {stamina iv} {from level} {to level}")
+)
+
+// parseIVs reads attack, defense and stamina IVs from 0 to 15
+func parseIVs(fields []string) (int, int, int, bool) {
+	if len(fields) < 3 {
+		return 0, 0, 0, false
+	}
+	ivs := make([]int, 3)
+	for i := range ivs {
+		iv, err := strconv.Atoi(fields[i])
+		if err != nil || iv < 0 || iv > 15 {
+			return 0, 0, 0, false
+		}
+		ivs[i] = iv
+	}
+	return ivs[0], ivs[1], ivs[2], true
+}
+
+// CPLevelTable creates the table of CP, HP and the power up cost so far at every half level from one level to another
+func CPLevelTable(p *pogo.Pokemon, attack, defense, stamina int, from float64, to float64, lang string) *Table {
+	title := fmt.Sprintf(Translate(lang, "%s %d/%d/%d - CP by level"), LocalName(lang, KindPokemon, p.Name), attack, defense, stamina)
+	table := NewTable(title).
+		SetPicture(PokemonPicture(p)).
+		SetHeaders(Translate(lang, "Level"), "CP", "HP", Translate(lang, "Stardust"), Translate(lang, "Candy"), Translate(lang, "XL")).
+		SetColWidths(40, 45, 35, 70, 45, 35)
+
+	cost := PowerUpCost{}
+	for level := from; level <= to; level += 0.5 {
+		table.AddRow(formatLevel(level),
+			strconv.Itoa(CalcCP(p.Stats, level, attack, defense, stamina)),
+			strconv.Itoa(CalcHP(p.Stats, level, stamina)),
+			strconv.Itoa(cost.Stardust), strconv.Itoa(cost.Candy), strconv.Itoa(cost.XLCandy)).
+			SetBackground(WHITE)
+		cost = cost.Add(LevelCost(level, CostModifiers{}))
+	}
+
+	total := CostToLevel(from, to, CostModifiers{})
+	summary := Translate(lang, "Level %v to %v costs %d stardust, %d candy and %d XL candy")
+	return table.SetSummary(fmt.Sprintf(summary, from, to, total.Stardust, total.Candy, total.XLCandy))
+}
+
+// PrintCPTableToDiscord prints the CP of a pokemon at every level with the cost to power it up
+func PrintCPTableToDiscord(b *botResponse) error {
+	if len(b.fields) < 5 {
+		return &botError{ERR_CPTABLE_COMMAND, ""}
+	}
+
+	attack, defense, stamina, ok := parseIVs(b.fields[2:5])
+	if !ok {
+		return &botError{ERR_CPTABLE_COMMAND, ""}
+	}
+
+	from, to := MinLevel, MaxLevel
+	for i, level := range []*float64{&from, &to} {
+		if len(b.fields) > 5+i {
+			val, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(b.fields[5+i]), "l"), 64)
+			if err != nil || !ValidLevel(val) || val > MaxLevel {
+				return &botError{ERR_LEVEL_INVALID, b.fields[5+i]}
+			}
+			*level = val
+		}
+	}
+	if from > to {
+		from, to = to, from
+	}
+
+	p, err := GetPokemon(strings.ToLower(b.fields[1]))
+	if err != nil {
+		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
+	}
+	if p.Stats.BaseAttack == 0 {
+		return &botError{ERR_NO_STATS, b.PokemonName(p)}
+	}
+
+	table := CPLevelTable(p, attack, defense, stamina, from, to, b.lang())
+	if b.useImages() {
+		b.SendTableToDiscord(fmt.Sprintf("CPTABLE-%s-%d-%d-%d-%v-%v", p.ID, attack, defense, stamina, from, to), table, FormatPNG)
+		return nil
+	}
+
+	lines := strings.Split(table.Text(), "\n")
+	pages := []*discordgo.MessageEmbed{}
+	for _, page := range SplitRows(lines[1:], PageRows) {
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			SetDescription(table.Summary).
+			AddField(table.Title, Example(lines[0]+"\n"+strings.Join(page, "\n"))).
+			SetThumbnail(p.API.Sprites.Front).MessageEmbed
+		pages = append(pages, emb)
+	}
+	b.PrintPagesToDiscord(pages)
+	return nil
+}
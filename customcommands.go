@@ -0,0 +1,255 @@
+package haynesbot
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Custom command errors
+var (
+	ERR_ADDCMD_COMMAND     = errors.New("Add a command for your server using !addcmd {name} {'embed'} {response}")
+	ERR_EDITCMD_COMMAND    = errors.New("Edit a command for your server using !editcmd {name} {'embed'} {response}")
+	ERR_DELCMD_COMMAND     = errors.New("Delete a command for your server using !delcmd {name}")
+	ERR_CMD_BUILTIN        = errors.New("That name is already used by a haynesbot command.")
+	ERR_CMD_EXISTS         = errors.New("That command already exists.")
+	ERR_CMD_MISSING        = errors.New("That command doesn't exist.")
+	ERR_NO_CUSTOM_COMMANDS = errors.New("No custom commands set. Add one with !addcmd")
+)
+
+// CustomCommand is a canned response a guild has added to the bot
+type CustomCommand struct {
+	Name     string `json:"Name"`
+	Response string `json:"Response"`
+	Embed    bool   `json:"Embed,omitempty"`
+}
+
+// Print prints the custom command response to discord
+func (cc CustomCommand) Print(b *botResponse) error {
+	msg := cc.Response
+	if b.guild != nil {
+		msg = b.guild.FormatMessage(msg, b.m.Author)
+	}
+
+	if cc.Embed {
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			SetDescription(msg).MessageEmbed
+		b.PrintEmbedToDiscord(emb)
+	} else {
+		b.PrintToDiscord(msg)
+	}
+
+	return nil
+}
+
+// GetCustomCommand gets a custom command for a guild by name
+func (guild *Guild) GetCustomCommand(name string) (CustomCommand, bool) {
+	for _, cc := range guild.Settings.Commands {
+		if cc.Name == name {
+			return cc, true
+		}
+	}
+	return CustomCommand{}, false
+}
+
+// AddCustomCommand adds a new custom command to a guild
+func (guild *Guild) AddCustomCommand(cc CustomCommand) error {
+	if _, ok := cmdMap[cc.Name]; ok {
+		return &botError{ERR_CMD_BUILTIN, cc.Name}
+	}
+	if _, _, ok := getSuffixCommand(cc.Name); ok {
+		return &botError{ERR_CMD_BUILTIN, cc.Name}
+	}
+	if _, ok := guild.GetCustomCommand(cc.Name); ok {
+		return &botError{ERR_CMD_EXISTS, cc.Name}
+	}
+	if _, ok := guild.GetAlias(cc.Name); ok {
+		return &botError{ERR_CMD_EXISTS, cc.Name}
+	}
+
+	guild.Settings.Commands = append(guild.Settings.Commands, cc)
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// EditCustomCommand replaces the response of an existing custom command for a guild
+func (guild *Guild) EditCustomCommand(cc CustomCommand) error {
+	for i, c := range guild.Settings.Commands {
+		if c.Name == cc.Name {
+			guild.Settings.Commands[i] = cc
+			guildSettings.add(guild).save(config.GuildFile)
+			return nil
+		}
+	}
+	return &botError{ERR_CMD_MISSING, cc.Name}
+}
+
+// DeleteCustomCommand removes a custom command from a guild
+func (guild *Guild) DeleteCustomCommand(name string) error {
+	for i, c := range guild.Settings.Commands {
+		if c.Name == name {
+			guild.Settings.Commands = append(guild.Settings.Commands[:i], guild.Settings.Commands[i+1:]...)
+			guildSettings.add(guild).save(config.GuildFile)
+			return nil
+		}
+	}
+	return &botError{ERR_CMD_MISSING, name}
+}
+
+// AddCommand allows the server owner to add a custom command
+func AddCommand(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	cc, err := b.parseCustomCommand(ERR_ADDCMD_COMMAND)
+	if err != nil {
+		return err
+	}
+
+	err = guild.AddCustomCommand(cc)
+	if err != nil {
+		return err
+	}
+
+	b.PrintToDiscord(b.T("Command %s added!", cc.Name))
+	return nil
+}
+
+// EditCommand allows the server owner to change the response of a custom command
+func EditCommand(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	cc, err := b.parseCustomCommand(ERR_EDITCMD_COMMAND)
+	if err != nil {
+		return err
+	}
+
+	err = guild.EditCustomCommand(cc)
+	if err != nil {
+		return err
+	}
+
+	b.PrintToDiscord(b.T("Command %s updated!", cc.Name))
+	return nil
+}
+
+// DeleteCommand allows the server owner to remove a custom command
+func DeleteCommand(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	if len(b.fields) < 2 {
+		return &botError{ERR_DELCMD_COMMAND, ""}
+	}
+
+	name := customCommandName(b.fields[1], guild)
+	err = guild.DeleteCustomCommand(name)
+	if err != nil {
+		return err
+	}
+
+	b.PrintToDiscord(b.T("Command %s deleted!", name))
+	return nil
+}
+
+// ListCommands prints the custom commands for a guild to discord
+func ListCommands(b *botResponse) error {
+	if b.guild == nil {
+		return &botError{ERR_NO_GUILD, ""}
+	}
+
+	if len(b.guild.Settings.Commands) == 0 {
+		return &botError{ERR_NO_CUSTOM_COMMANDS, ""}
+	}
+
+	names := []string{}
+	for _, cc := range b.guild.Settings.Commands {
+		names = append(names, b.guild.Settings.BotPrefix+cc.Name)
+	}
+	sort.Strings(names)
+
+	emb := b.NewEmbed().
+		SetColorRole(ColorInfo).
+		AddField(b.T("Server Commands"), strings.Join(names, "\n"))
+	b.addPrefixesField(emb, b.guild)
+	b.PrintEmbedToDiscord(emb.MessageEmbed)
+	return nil
+}
+
+// ownerGuild gets the guild for the message and makes sure the author owns it
+func (b *botResponse) ownerGuild() (*Guild, error) {
+	if b.guild == nil {
+		return nil, &botError{ERR_NO_GUILD, ""}
+	}
+
+	if !b.guild.IsManaged() {
+		return nil, &botError{ERR_NOT_MANAGED, ""}
+	}
+
+	if !b.guild.IsOwner(b.m.Author) {
+		return nil, &botError{ERR_NOT_OWNER, ""}
+	}
+
+	return b.guild, nil
+}
+
+// parseCustomCommand reads a custom command from "!addcmd {name} {'embed'} {response}"
+func (b *botResponse) parseCustomCommand(usage error) (CustomCommand, error) {
+	if len(b.fields) < 3 {
+		return CustomCommand{}, &botError{usage, ""}
+	}
+
+	cc := CustomCommand{Name: customCommandName(b.fields[1], b.guild)}
+	skip := 2
+	if strings.ToLower(b.fields[2]) == "embed" {
+		if len(b.fields) < 4 {
+			return CustomCommand{}, &botError{usage, ""}
+		}
+		cc.Embed = true
+		skip = 3
+	}
+
+	cc.Response = b.rawArgs(skip)
+	if cc.Name == "" || cc.Response == "" {
+		return CustomCommand{}, &botError{usage, ""}
+	}
+
+	return cc, nil
+}
+
+// customCommandName normalizes the name of a custom command, dropping any of the guild's prefixes if one was typed
+func customCommandName(name string, guild *Guild) string {
+	if prefix, ok := guild.MatchPrefix(name); ok {
+		name = name[len(prefix):]
+	}
+	return strings.ToLower(name)
+}
+
+// rawArgs returns the message content after the first n fields with its original spacing and newlines
+func (b *botResponse) rawArgs(n int) string {
+	content := b.m.Content
+	for i := 0; i < n && i < len(b.fields); i++ {
+		idx := strings.Index(content, b.fields[i])
+		if idx < 0 {
+			return strings.Join(b.fields[n:], " ")
+		}
+		content = content[idx+len(b.fields[i]):]
+	}
+	return strings.TrimSpace(content)
+}
+
+// customCommand returns the BotCommand for a guild's custom command
+func customCommand(cc CustomCommand) *BotCommand {
+	return &BotCommand{
+		Name: cc.Name,
+		Do:   cc.Print,
+	}
+}
@@ -0,0 +1,18 @@
+package haynesbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRawArgs(t *testing.T) {
+	content := "!addcmd rules  1. Be nice\n2. No spoofing"
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{Content: content}}
+	b := NewBotResponse(nil, m, strings.Fields(content))
+
+	if got, want := b.rawArgs(2), "1. Be nice\n2. No spoofing"; got != want {
+		t.Errorf("rawArgs(2) = %q, want %q", got, want)
+	}
+}
@@ -1,12 +1,17 @@
 package haynesbot
 
 import (
-    "fmt"
-    "github.com/bwmarrin/discordgo"
-    )
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 type Embed struct {
-    *discordgo.MessageEmbed
+	*discordgo.MessageEmbed
+	theme   *Theme
+	compact bool
 }
 
 const (
@@ -17,6 +22,7 @@ const (
 	EmbedLimitField       = 25
 	EmbedLimitFooter      = 2048
 	EmbedLimit            = 4000
+	MessageLimit          = 2000
 )
 
 func Example(s string) string {
@@ -24,23 +30,20 @@ func Example(s string) string {
 }
 
 func NewEmbed() *Embed {
-    return &Embed{&discordgo.MessageEmbed{}}
+	return &Embed{MessageEmbed: &discordgo.MessageEmbed{}}
 }
 
 func (e *Embed) SetTitle(name string) *Embed {
-	e.Title = name
+	e.Title = truncate(name, EmbedLimitTitle)
 	return e
 }
 
 func (e *Embed) SetDescription(description string) *Embed {
-	if len(description) > 2048 {
-		description = description[:2048]
-	}
-	e.Description = description
+	e.Description = truncate(description, EmbedLimitDescription)
 	return e
 }
 
-//SetAuthor ...
+// SetAuthor ...
 func (e *Embed) SetAuthor(args ...string) *Embed {
 	var (
 		name     string
@@ -55,7 +58,7 @@ func (e *Embed) SetAuthor(args ...string) *Embed {
 	if len(args) > 0 {
 		name = args[0]
 	}
-	if len(args) > 1 {
+	if len(args) > 1 && !e.compact {
 		iconURL = args[1]
 	}
 	if len(args) > 2 {
@@ -75,12 +78,12 @@ func (e *Embed) SetAuthor(args ...string) *Embed {
 	return e
 }
 
-//SetImage ...
+// SetImage ...
 func (e *Embed) SetImage(args ...string) *Embed {
 	var URL string
 	var proxyURL string
 
-	if len(args) == 0 {
+	if len(args) == 0 || e.compact {
 		return e
 	}
 	if len(args) > 0 {
@@ -96,12 +99,12 @@ func (e *Embed) SetImage(args ...string) *Embed {
 	return e
 }
 
-//SetThumbnail ...
+// SetThumbnail ...
 func (e *Embed) SetThumbnail(args ...string) *Embed {
 	var URL string
 	var proxyURL string
 
-	if len(args) == 0 {
+	if len(args) == 0 || e.compact {
 		return e
 	}
 	if len(args) > 0 {
@@ -117,20 +120,17 @@ func (e *Embed) SetThumbnail(args ...string) *Embed {
 	return e
 }
 
+// AddField adds a field to the embed, splitting values that are too long into more fields
 func (e *Embed) AddField(name, value string) *Embed {
-	if len(value) > 1024 {
-		value = value[:1024]
-	}
+	name = truncate(name, EmbedLimitFieldName)
 
-	if len(name) > 1024 {
-		name = name[:1024]
+	for _, v := range splitFieldValue(value) {
+		e.Fields = append(e.Fields, &discordgo.MessageEmbedField{
+			Name:  name,
+			Value: v,
+		})
 	}
 
-	e.Fields = append(e.Fields, &discordgo.MessageEmbedField{
-		Name:  name,
-		Value: value,
-	})
-
 	return e
 }
 
@@ -147,6 +147,30 @@ func (e *Embed) SetColor(clr int) *Embed {
 	return e
 }
 
+// SetFooter sets the footer text
+func (e *Embed) SetFooter(text string) *Embed {
+	e.Footer = &discordgo.MessageEmbedFooter{Text: truncate(text, EmbedLimitFooter)}
+	return e
+}
+
+// SetCompact leaves out images, thumbnails and author icons
+func (e *Embed) SetCompact(compact bool) *Embed {
+	e.compact = compact
+	return e
+}
+
+// SetTheme sets the theme used for color roles
+func (e *Embed) SetTheme(t *Theme) *Embed {
+	e.theme = t
+	return e
+}
+
+// SetColorRole sets the color the theme uses for what the embed shows
+func (e *Embed) SetColorRole(role ColorRole) *Embed {
+	e.Color = e.theme.Color(role)
+	return e
+}
+
 func (e *Embed) Truncate() *Embed {
 	e.TruncateDescription()
 	e.TruncateFields()
@@ -157,43 +181,136 @@ func (e *Embed) Truncate() *Embed {
 
 // TruncateFields truncates fields that are too long
 func (e *Embed) TruncateFields() *Embed {
-	if len(e.Fields) > 25 {
+	if len(e.Fields) > EmbedLimitField {
 		e.Fields = e.Fields[:EmbedLimitField]
 	}
 
 	for _, v := range e.Fields {
-
-		if len(v.Name) > EmbedLimitFieldName {
-			v.Name = v.Name[:EmbedLimitFieldName]
-		}
-
-		if len(v.Value) > EmbedLimitFieldValue {
-			v.Value = v.Value[:EmbedLimitFieldValue]
-		}
-
+		v.Name = truncate(v.Name, EmbedLimitFieldName)
+		v.Value = truncate(v.Value, EmbedLimitFieldValue)
 	}
 	return e
 }
 
 func (e *Embed) TruncateDescription() *Embed {
-	if len(e.Description) > EmbedLimitDescription {
-		e.Description = e.Description[:EmbedLimitDescription]
-	}
+	e.Description = truncate(e.Description, EmbedLimitDescription)
 	return e
 }
 
 // TruncateTitle ...
 func (e *Embed) TruncateTitle() *Embed {
-	if len(e.Title) > EmbedLimitTitle {
-		e.Title = e.Title[:EmbedLimitTitle]
-	}
+	e.Title = truncate(e.Title, EmbedLimitTitle)
 	return e
 }
 
 // TruncateFooter ...
 func (e *Embed) TruncateFooter() *Embed {
-	if e.Footer != nil && len(e.Footer.Text) > EmbedLimitFooter {
-		e.Footer.Text = e.Footer.Text[:EmbedLimitFooter]
+	if e.Footer != nil {
+		e.Footer.Text = truncate(e.Footer.Text, EmbedLimitFooter)
 	}
 	return e
-}
\ No newline at end of file
+}
+
+// Length returns the number of characters in the embed the way discord counts them
+func (e *Embed) Length() int {
+	length := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, f := range e.Fields {
+		length += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	if e.Footer != nil {
+		length += utf8.RuneCountInString(e.Footer.Text)
+	}
+	if e.Author != nil {
+		length += utf8.RuneCountInString(e.Author.Name)
+	}
+	return length
+}
+
+// Split splits the embed into as many embeds as needed to stay within the field and size limits.
+// The first embed keeps the title, description, author and thumbnail, the last one keeps the footer.
+func (e *Embed) Split() []*discordgo.MessageEmbed {
+	e.TruncateDescription()
+	e.TruncateFooter()
+	e.TruncateTitle()
+	for _, v := range e.Fields {
+		v.Name = truncate(v.Name, EmbedLimitFieldName)
+		v.Value = truncate(v.Value, EmbedLimitFieldValue)
+	}
+	if len(e.Fields) <= EmbedLimitField && e.Length() <= EmbedLimit {
+		return []*discordgo.MessageEmbed{e.MessageEmbed}
+	}
+
+	first := *e.MessageEmbed
+	first.Fields = nil
+	first.Footer = nil
+	current := &Embed{MessageEmbed: &first}
+	embeds := []*discordgo.MessageEmbed{current.MessageEmbed}
+
+	for _, f := range e.Fields {
+		size := utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+		if len(current.Fields) == EmbedLimitField || (len(current.Fields) > 0 && current.Length()+size > EmbedLimit) {
+			current = &Embed{MessageEmbed: &discordgo.MessageEmbed{Color: e.Color}}
+			embeds = append(embeds, current.MessageEmbed)
+		}
+		current.Fields = append(current.Fields, f)
+	}
+	current.Footer = e.Footer
+
+	return embeds
+}
+
+// truncate cuts a string to at most limit characters without splitting a character in half
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+// SplitText splits text into chunks of at most limit characters, breaking at newlines when it can
+func SplitText(s string, limit int) []string {
+	chunks := []string{}
+	current := ""
+	for _, line := range strings.SplitAfter(s, "\n") {
+		for utf8.RuneCountInString(line) > limit {
+			if current != "" {
+				chunks = append(chunks, current)
+				current = ""
+			}
+			runes := []rune(line)
+			chunks = append(chunks, string(runes[:limit]))
+			line = string(runes[limit:])
+		}
+		if utf8.RuneCountInString(current)+utf8.RuneCountInString(line) > limit {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		current += line
+	}
+	if current != "" || len(chunks) == 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// splitFieldValue splits a field value that is too long, keeping code blocks made with Example intact
+func splitFieldValue(value string) []string {
+	if utf8.RuneCountInString(value) <= EmbedLimitFieldValue {
+		return []string{value}
+	}
+
+	start, end := "", ""
+	if strings.HasPrefix(value, "```") && strings.HasSuffix(value, "```") {
+		if i := strings.Index(value, "\n"); i > 0 {
+			start, end = value[:i+1], "\n```"
+			value = strings.TrimSuffix(strings.TrimSuffix(value[i+1:], "```"), "\n")
+		}
+	}
+
+	limit := EmbedLimitFieldValue - utf8.RuneCountInString(start+end)
+	values := []string{}
+	for _, chunk := range SplitText(value, limit) {
+		values = append(values, start+strings.TrimSuffix(chunk, "\n")+end)
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+package haynesbot
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	got := truncate("Pokémon", 4)
+	if got != "Poké" || !utf8.ValidString(got) {
+		t.Errorf("truncate(Pokémon, 4) = %q, want Poké", got)
+	}
+}
+
+func TestEmbedSplit(t *testing.T) {
+	emb := NewEmbed().SetTitle("Raid Chart")
+	for i := 0; i < 30; i++ {
+		emb.AddField("Pokémon", strings.Repeat("é", 200))
+	}
+
+	embeds := emb.Split()
+	if len(embeds) < 2 {
+		t.Fatalf("Split gave %d embeds, want at least 2", len(embeds))
+	}
+
+	fields := 0
+	for _, e := range embeds {
+		if len(e.Fields) > EmbedLimitField {
+			t.Errorf("embed has %d fields", len(e.Fields))
+		}
+		if l := (&Embed{MessageEmbed: e}).Length(); l > EmbedLimit {
+			t.Errorf("embed has %d characters", l)
+		}
+		fields += len(e.Fields)
+	}
+	if fields != 30 {
+		t.Errorf("Split kept %d fields, want 30", fields)
+	}
+}
+
+func TestAddFieldSplitsCodeBlocks(t *testing.T) {
+	rows := []string{}
+	for i := 0; i < 100; i++ {
+		rows = append(rows, "100% 15 15 15 2000 2500 3000")
+	}
+
+	emb := NewEmbed().AddField("Raid Chart", Example(strings.Join(rows, "\n")))
+	if len(emb.Fields) < 2 {
+		t.Fatalf("AddField gave %d fields, want at least 2", len(emb.Fields))
+	}
+	for _, f := range emb.Fields {
+		if utf8.RuneCountInString(f.Value) > EmbedLimitFieldValue {
+			t.Errorf("field value has %d characters", utf8.RuneCountInString(f.Value))
+		}
+		if !strings.HasPrefix(f.Value, "```css\n") || !strings.HasSuffix(f.Value, "\n```") {
+			t.Errorf("field value isn't a code block: %q", f.Value)
+		}
+	}
+}
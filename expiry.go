@@ -0,0 +1,272 @@
+package haynesbot
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Reply lifetime limits
+const (
+	MinReplyLifetime = 10 * time.Second
+	MaxReplyLifetime = 24 * time.Hour
+)
+
+// Expiry errors
+var (
+	ERR_EXPIRE_COMMAND = errors.New("Set how long haynesbot replies stay using !expire {duration|off|reset} {'here'} or !expire commands {on|off}. Example: !expire 10m here")
+	ERR_EXPIRE_RANGE   = errors.New("Reply lifetime has to be between 10s and 24h.")
+)
+
+// pendingDeletion is a message that will be deleted once it expires
+type pendingDeletion struct {
+	ChannelID string    `json:"ChannelID"`
+	MessageID string    `json:"MessageID"`
+	At        time.Time `json:"At"`
+}
+
+// key identifies the message, so scheduling it again replaces the old deletion
+func (p pendingDeletion) key() string {
+	return p.ChannelID + "/" + p.MessageID
+}
+
+// expiryScheduler deletes expired messages and saves the ones still pending so they survive restarts.
+// Changes are saved on the next tick instead of on every Schedule, so replies don't wait on the disk.
+type expiryScheduler struct {
+	sync.Mutex
+	file    string
+	pending map[string]pendingDeletion
+	dirty   bool
+}
+
+var expiring = &expiryScheduler{}
+
+// Start loads saved deletions and starts deleting expired messages
+func (es *expiryScheduler) Start(s *discordgo.Session, file string) {
+	es.load(file)
+
+	go func() {
+		for range time.Tick(5 * time.Second) {
+			es.deleteExpired(s)
+			es.flush()
+		}
+	}()
+}
+
+// load reads the deletions saved in a file and saves to it from then on
+func (es *expiryScheduler) load(file string) {
+	es.Lock()
+	defer es.Unlock()
+
+	es.file = file
+	es.pending = make(map[string]pendingDeletion)
+	saved := []pendingDeletion{}
+	if data, err := ioutil.ReadFile(file); err == nil {
+		if err := json.Unmarshal(data, &saved); err != nil {
+			log.Println("Unable to read pending deletions: ", err.Error())
+		}
+	} else if !os.IsNotExist(err) {
+		log.Println("Unable to read pending deletions: ", err.Error())
+	}
+	for _, p := range saved {
+		es.pending[p.key()] = p
+	}
+}
+
+// Schedule deletes a message after the given lifetime, replacing any deletion already scheduled for it
+func (es *expiryScheduler) Schedule(channelID, messageID string, lifetime time.Duration) {
+	es.Lock()
+	defer es.Unlock()
+
+	if es.pending == nil {
+		es.pending = make(map[string]pendingDeletion)
+	}
+	p := pendingDeletion{channelID, messageID, time.Now().Add(lifetime)}
+	es.pending[p.key()] = p
+	es.dirty = true
+}
+
+// takeExpired removes and returns the deletions that are due
+func (es *expiryScheduler) takeExpired(now time.Time) []pendingDeletion {
+	es.Lock()
+	defer es.Unlock()
+
+	expired := []pendingDeletion{}
+	for key, p := range es.pending {
+		if !p.At.After(now) {
+			expired = append(expired, p)
+			delete(es.pending, key)
+		}
+	}
+	if len(expired) > 0 {
+		es.dirty = true
+	}
+	return expired
+}
+
+func (es *expiryScheduler) deleteExpired(s *discordgo.Session) {
+	for _, p := range es.takeExpired(time.Now()) {
+		_ = s.ChannelMessageDelete(p.ChannelID, p.MessageID)
+	}
+}
+
+// flush writes the pending deletions to disk if they changed since the last save
+func (es *expiryScheduler) flush() {
+	es.Lock()
+	defer es.Unlock()
+
+	if !es.dirty || es.file == "" {
+		return
+	}
+
+	pending := make([]pendingDeletion, 0, len(es.pending))
+	for _, p := range es.pending {
+		pending = append(pending, p)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].At.Before(pending[j].At) })
+
+	out, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+
+	if err := ioutil.WriteFile(es.file, out, 0600); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	es.dirty = false
+}
+
+// expiryFile returns where pending deletions are saved
+func expiryFile() string {
+	if config.ExpiryFile != "" {
+		return config.ExpiryFile
+	}
+	return path.Join(path.Dir(config.GuildFile), "expiring.json")
+}
+
+// ReplyLifetime returns how long bot replies stay in a channel before they're deleted, 0 if they stay
+func (guild *Guild) ReplyLifetime(channelID string) time.Duration {
+	if seconds, ok := guild.Settings.ChannelReplyLifetimes[channelID]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(guild.Settings.ReplyLifetime) * time.Second
+}
+
+// SetReplyLifetime sets how long bot replies stay in a guild, or in a single channel if channelID isn't empty
+func (guild *Guild) SetReplyLifetime(channelID string, lifetime time.Duration) error {
+	seconds := int(lifetime / time.Second)
+	if channelID == "" {
+		guild.Settings.ReplyLifetime = seconds
+	} else {
+		if guild.Settings.ChannelReplyLifetimes == nil {
+			guild.Settings.ChannelReplyLifetimes = make(map[string]int)
+		}
+		guild.Settings.ChannelReplyLifetimes[channelID] = seconds
+	}
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// ResetReplyLifetime makes a channel use the reply lifetime of the guild again
+func (guild *Guild) ResetReplyLifetime(channelID string) error {
+	delete(guild.Settings.ChannelReplyLifetimes, channelID)
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// SetExpireCommands sets whether the command messages are deleted along with the replies
+func (guild *Guild) SetExpireCommands(expire bool) error {
+	guild.Settings.ExpireCommands = expire
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// scheduleExpiry schedules the replies to a command for deletion if the channel has a reply lifetime
+func (b *botResponse) scheduleExpiry() {
+	if b.guild == nil || len(b.replies) == 0 {
+		return
+	}
+
+	lifetime := b.guild.ReplyLifetime(b.m.ChannelID)
+	if lifetime <= 0 {
+		return
+	}
+
+	for _, r := range b.replies {
+		expiring.Schedule(r.ChannelID, r.MessageID, lifetime)
+	}
+
+	if b.guild.Settings.ExpireCommands && b.canManageMessages() {
+		expiring.Schedule(b.m.ChannelID, b.m.ID, lifetime)
+	}
+}
+
+// canManageMessages returns true if the bot can delete other users' messages in the channel
+func (b *botResponse) canManageMessages() bool {
+	perms, err := b.s.State.UserChannelPermissions(BotID, b.m.ChannelID)
+	if err != nil {
+		return false
+	}
+	return perms&discordgo.PermissionManageMessages != 0
+}
+
+// SetExpire allows the server owner to have bot replies deleted after a while
+func SetExpire(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	if len(b.fields) < 2 {
+		return &botError{ERR_EXPIRE_COMMAND, ""}
+	}
+
+	channelID := ""
+	where := b.T("this server")
+	if len(b.fields) > 2 && b.fields[2] == "here" {
+		channelID = b.m.ChannelID
+		where = b.T("this channel")
+	}
+
+	switch value := b.fields[1]; value {
+	case "commands":
+		expire := len(b.fields) > 2 && b.fields[2] == "on"
+		guild.SetExpireCommands(expire)
+		if expire {
+			b.PrintToDiscord(b.T("Commands will be deleted along with the replies (needs Manage Messages)."))
+		} else {
+			b.PrintToDiscord(b.T("Commands will no longer be deleted."))
+		}
+	case "off":
+		guild.SetReplyLifetime(channelID, 0)
+		b.PrintToDiscord(b.T("Replies will stay in %s.", where))
+	case "reset":
+		if channelID == "" {
+			return &botError{ERR_EXPIRE_COMMAND, ""}
+		}
+		guild.ResetReplyLifetime(channelID)
+		b.PrintToDiscord(b.T("This channel now uses the server reply lifetime."))
+	default:
+		lifetime, err := time.ParseDuration(value)
+		if err != nil {
+			return &botError{ERR_EXPIRE_COMMAND, ""}
+		}
+		if lifetime < MinReplyLifetime || lifetime > MaxReplyLifetime {
+			return &botError{ERR_EXPIRE_RANGE, ""}
+		}
+		guild.SetReplyLifetime(channelID, lifetime)
+		b.PrintToDiscord(b.T("Replies in %s will be deleted after %s.", where, lifetime))
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package haynesbot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpiryScheduler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "haynesbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "expiring.json")
+
+	es := &expiryScheduler{}
+	es.load(file)
+	es.Schedule("c", "1", time.Minute)
+	es.Schedule("c", "2", time.Hour)
+
+	// Editing a command schedules its reply again, which replaces the first deletion
+	es.Schedule("c", "1", 2*time.Hour)
+	if len(es.pending) != 2 {
+		t.Fatalf("Got %d pending deletions, want 2", len(es.pending))
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("Schedule saved to disk before a flush")
+	}
+
+	if expired := es.takeExpired(time.Now().Add(90 * time.Minute)); len(expired) != 1 || expired[0].MessageID != "2" {
+		t.Errorf("Expired after 90m = %v, want only message 2", expired)
+	}
+
+	es.flush()
+	reloaded := &expiryScheduler{}
+	reloaded.load(file)
+	p, ok := reloaded.pending["c/1"]
+	if len(reloaded.pending) != 1 || !ok {
+		t.Fatalf("Reloaded %v, want only message 1", reloaded.pending)
+	}
+	if until := time.Until(p.At); until < 119*time.Minute {
+		t.Errorf("Message 1 expires in %v, want the replaced 2h", until)
+	}
+
+	if expired := reloaded.takeExpired(time.Now().Add(3 * time.Hour)); len(expired) != 1 || len(reloaded.pending) != 0 {
+		t.Errorf("Expired after 3h = %v with %d left", expired, len(reloaded.pending))
+	}
+}
@@ -42,8 +42,23 @@ type GuildSetting struct {
 	Managed   bool   `json:"Managed"`
 	Teams     bool   `json:"Teams"`
 	BotPrefix string `json:"Prefix,omitempty"`
-	Welcome   string `json:"Welcome,omitempty"`
-	Goodbye   string `json:"Goodbye,omitempty"`
+
+	Prefixes         []string `json:"Prefixes,omitempty"`
+	PrefixIgnoreCase bool     `json:"PrefixIgnoreCase,omitempty"`
+	Welcome          string   `json:"Welcome,omitempty"`
+	Goodbye          string   `json:"Goodbye,omitempty"`
+
+	Commands []CustomCommand   `json:"Commands,omitempty"`
+	Aliases  map[string]string `json:"Aliases,omitempty"`
+
+	ReplyLifetime         int            `json:"ReplyLifetime,omitempty"`
+	ChannelReplyLifetimes map[string]int `json:"ChannelReplyLifetimes,omitempty"`
+	ExpireCommands        bool           `json:"ExpireCommands,omitempty"`
+
+	Theme *Theme `json:"Theme,omitempty"`
+
+	Prefs        map[string]string            `json:"Prefs,omitempty"`
+	ChannelPrefs map[string]map[string]string `json:"ChannelPrefs,omitempty"`
 }
 
 // Guild is a representation of a single discord guild
@@ -97,9 +112,10 @@ func (guild *Guild) Update() error {
 	return nil
 }
 
-// SetPrefix sets the bot prefix for a guild
+// SetPrefix sets the bot prefix for a guild, replacing any other prefixes
 func (guild *Guild) SetPrefix(pre string) error {
 	guild.Settings.BotPrefix = pre
+	guild.Settings.Prefixes = nil
 	guildSettings.add(guild).save(config.GuildFile)
 	return nil
 }
@@ -234,16 +250,7 @@ func (guild *Guild) PrintWelcome(user *discordgo.User) error {
 		return &botError{ERR_MISSING_CHANNEL, "welcome"}
 	}
 
-	var messageStrReplace = map[string]string{
-		"{mention}": user.Mention(),
-		"{guild}":   guild.Name,
-		"{user}":    user.Username,
-	}
-
-	message := guild.Settings.Welcome
-	for str, rep := range messageStrReplace {
-		message = strings.Replace(message, str, rep, -1)
-	}
+	message := guild.FormatMessage(guild.Settings.Welcome, user)
 
 	_, _ = goBot.ChannelMessageSend(welcomeChannel, message)
 
@@ -265,20 +272,26 @@ func (guild *Guild) PrintGoodbye(user *discordgo.User) error {
 		return &botError{ERR_MISSING_CHANNEL, "welcome"}
 	}
 
+	message := guild.FormatMessage(guild.Settings.Goodbye, user)
+
+	_, _ = goBot.ChannelMessageSend(welcomeChannel, message)
+
+	return nil
+}
+
+// FormatMessage replaces the {mention}, {user} and {guild} placeholders in a message
+func (guild *Guild) FormatMessage(msg string, user *discordgo.User) string {
 	var messageStrReplace = map[string]string{
 		"{mention}": user.Mention(),
 		"{guild}":   guild.Name,
 		"{user}":    user.Username,
 	}
 
-	message := guild.Settings.Goodbye
 	for str, rep := range messageStrReplace {
-		message = strings.Replace(message, str, rep, -1)
+		msg = strings.Replace(msg, str, rep, -1)
 	}
 
-	_, _ = goBot.ChannelMessageSend(welcomeChannel, message)
-
-	return nil
+	return msg
 }
 
 // IsOwner returns true if the given user is the owner of the guild
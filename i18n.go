@@ -0,0 +1,226 @@
+package haynesbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language the messages in the code are written in
+const DefaultLanguage = "en"
+
+// Catalog holds the translations of messages into one language, keyed by the English message.
+// Translation files are json like {"Name": "Español", "Messages": {"Moves for %s": "Movimientos de %s"}}
+// and can also translate pokemon, move and type names, keyed by the English name.
+type Catalog struct {
+	Name     string                 `json:"Name"`
+	Messages map[string]Translation `json:"Messages"`
+	Pokemon  map[string]string      `json:"Pokemon"`
+	Moves    map[string]string      `json:"Moves"`
+	Types    map[string]string      `json:"Types"`
+}
+
+// Translation is a translated message with a form for each plural category, like "one" and "other".
+// Messages without plurals only have "other".
+type Translation map[string]string
+
+// UnmarshalJSON reads a translation from a string, or from an object of plural forms
+func (t *Translation) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = Translation{"other": s}
+		return nil
+	}
+
+	forms := map[string]string{}
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	*t = Translation(forms)
+	return nil
+}
+
+var catalogs = struct {
+	sync.RWMutex
+	m map[string]*Catalog
+}{m: make(map[string]*Catalog)}
+
+// LoadCatalogs reads every {language}.json translation file in a directory
+func LoadCatalogs(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".json" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path.Join(dir, f.Name()))
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		c := &Catalog{}
+		if err := json.Unmarshal(data, c); err != nil {
+			log.Println("Unable to read translations", f.Name(), err)
+			continue
+		}
+		AddCatalog(strings.TrimSuffix(f.Name(), ".json"), c)
+	}
+	return nil
+}
+
+// AddCatalog adds the translations for a language
+func AddCatalog(lang string, c *Catalog) {
+	catalogs.Lock()
+	catalogs.m[strings.ToLower(lang)] = c
+	catalogs.Unlock()
+	nameIndex.add(c)
+}
+
+// Languages returns the languages the bot can reply in. Some only translate names, and their messages are in English.
+func Languages() []string {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+
+	langs := []string{DefaultLanguage}
+	for lang := range catalogs.m {
+		if lang != DefaultLanguage {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// HasLanguage checks if the bot can reply in a language, or in the language it's a variant of
+func HasLanguage(lang string) bool {
+	return baseLanguage(lang) == DefaultLanguage || findCatalog(lang) != nil
+}
+
+// baseLanguage returns the language without its region, pt for pt-br
+func baseLanguage(lang string) string {
+	return strings.SplitN(strings.ToLower(lang), "-", 2)[0]
+}
+
+// findCatalog returns the catalog for a language, falling back to the language without its region
+func findCatalog(lang string) *Catalog {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+
+	lang = strings.ToLower(lang)
+	if c, ok := catalogs.m[lang]; ok {
+		return c
+	}
+	return catalogs.m[baseLanguage(lang)]
+}
+
+// pluralForm returns the plural category of a number in a language
+func pluralForm(lang string, n int) string {
+	switch baseLanguage(lang) {
+	case "ja", "zh", "ko", "th", "vi", "id":
+		return "other"
+	case "fr":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+	case "pt":
+		// Brazilian Portuguese counts 0 as singular, European Portuguese doesn't
+		if n == 1 || (n == 0 && strings.ToLower(lang) != "pt-pt") {
+			return "one"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// Translate returns a message in a language, or the English message when it hasn't been translated
+func Translate(lang string, msg string) string {
+	if c := findCatalog(lang); c != nil {
+		if t, ok := c.Messages[msg]; ok && t["other"] != "" {
+			return t["other"]
+		}
+	}
+	return msg
+}
+
+// TranslatePlural returns the form of a message for n in a language. The English singular is the key.
+func TranslatePlural(lang string, one string, other string, n int) string {
+	form := pluralForm(lang, n)
+	if c := findCatalog(lang); c != nil {
+		if t, ok := c.Messages[one]; ok {
+			if msg, ok := t[form]; ok {
+				return msg
+			}
+			if msg, ok := t["other"]; ok {
+				return msg
+			}
+		}
+	}
+
+	if n == 1 {
+		return one
+	}
+	return other
+}
+
+// lang returns the language the user wants replies in
+func (b *botResponse) lang() string {
+	return b.Pref("language")
+}
+
+// T translates a message into the language of the user, formatting it with args if there are any
+func (b *botResponse) T(msg string, args ...interface{}) string {
+	msg = Translate(b.lang(), msg)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Tn translates a message with a count into the language of the user, and formats it with n.
+// Singular forms like "the first row" don't need to include the count.
+func (b *botResponse) Tn(one string, other string, n int) string {
+	msg := TranslatePlural(b.lang(), one, other, n)
+	if !strings.Contains(msg, "%") {
+		return msg
+	}
+	return fmt.Sprintf(msg, n)
+}
+
+// SetLanguageCommand lets users choose the language of replies, or owners for a channel or the whole server
+func SetLanguageCommand(b *botResponse) error {
+	args, scope := splitScope(b.fields[1:])
+	if len(args) != 1 {
+		names := []string{}
+		for _, lang := range Languages() {
+			name := "English"
+			if c := findCatalog(lang); c != nil && c.Name != "" {
+				name = c.Name
+				if len(c.Messages) == 0 {
+					name += ", " + b.T("names only")
+				}
+			}
+			names = append(names, fmt.Sprintf("%s (%s)", lang, name))
+		}
+		b.PrintToDiscord(b.T("Replies are in %s. Languages: %s", b.lang(), strings.Join(names, ", ")))
+		return nil
+	}
+
+	msg, err := b.setPref("language", args[0], scope)
+	if err != nil {
+		return err
+	}
+	b.PrintToDiscord(msg)
+	return nil
+}
@@ -0,0 +1,210 @@
+package haynesbot
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/haynesherway/pogo"
+)
+
+// useCatalogs adds catalogs until the returned func is called, which puts back the catalogs and the names they index
+func useCatalogs(add map[string]*Catalog) func() {
+	catalogs.Lock()
+	oldCatalogs, oldNames := catalogs.m, nameIndex
+	catalogs.m = make(map[string]*Catalog)
+	nameIndex = &names{english: make(map[string]map[string]string)}
+	for lang, c := range oldCatalogs {
+		catalogs.m[lang] = c
+		nameIndex.add(c)
+	}
+	catalogs.Unlock()
+
+	for lang, c := range add {
+		AddCatalog(lang, c)
+	}
+	return func() {
+		catalogs.Lock()
+		catalogs.m, nameIndex = oldCatalogs, oldNames
+		catalogs.Unlock()
+	}
+}
+
+func TestTranslatePlural(t *testing.T) {
+	defer useCatalogs(map[string]*Catalog{"pt": {Name: "Português", Messages: map[string]Translation{
+		"%d possible IV combination": {"one": "%d combinação", "other": "%d combinações"},
+		"Moves for %s":               {"other": "Ataques de %s"},
+	}}})()
+
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "%d possible IV combination"},
+		{"en", 0, "%d possible IV combinations"},
+		{"pt-br", 0, "%d combinação"},
+		{"pt-pt", 0, "%d combinações"},
+		{"pt", 2, "%d combinações"},
+		{"es", 1, "%d possible IV combination"},
+	}
+	for _, test := range tests {
+		if got := TranslatePlural(test.lang, "%d possible IV combination", "%d possible IV combinations", test.n); got != test.want {
+			t.Errorf("TranslatePlural(%s, %d) = %q, want %q", test.lang, test.n, got, test.want)
+		}
+	}
+
+	if got := Translate("pt-BR", "Moves for %s"); got != "Ataques de %s" {
+		t.Errorf("Translate fell back to %q", got)
+	}
+	if got := Translate("pt", "Raid Chart"); got != "Raid Chart" {
+		t.Errorf("Untranslated message changed to %q", got)
+	}
+}
+
+// messageKeys finds every message the code translates: literals passed to T, Tn, Translate and TranslatePlural,
+// error messages, command info, league titles and preference scopes
+func messageKeys(t *testing.T) map[string]string {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]string{}
+	add := func(e ast.Expr) {
+		if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if key, err := strconv.Unquote(lit.Value); err == nil {
+				keys[key] = fset.Position(lit.Pos()).String()
+			}
+		}
+	}
+	// addElts adds the i-th field of every struct literal in a slice literal, like the Info of each BotCommand
+	addElts := func(e ast.Expr, i int) {
+		if list, ok := e.(*ast.CompositeLit); ok {
+			for _, elt := range list.Elts {
+				if item, ok := elt.(*ast.CompositeLit); ok && len(item.Elts) > i {
+					add(item.Elts[i])
+				}
+			}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.CallExpr:
+					name := ""
+					switch fun := n.Fun.(type) {
+					case *ast.Ident:
+						name = fun.Name
+					case *ast.SelectorExpr:
+						name = fun.Sel.Name
+						if x, ok := fun.X.(*ast.Ident); ok && x.Name == "errors" && name == "New" {
+							name = "errors.New"
+						}
+					}
+					switch {
+					case (name == "T" || name == "Tn" || name == "errors.New") && len(n.Args) > 0:
+						add(n.Args[0])
+					case (name == "Translate" || name == "TranslatePlural") && len(n.Args) > 1:
+						add(n.Args[1])
+					}
+				case *ast.ValueSpec:
+					for i, id := range n.Names {
+						if i >= len(n.Values) {
+							break
+						}
+						switch {
+						case id.Name == "botCommands":
+							addElts(n.Values[i], 2)
+						case id.Name == "leagues":
+							addElts(n.Values[i], 1)
+						case strings.HasPrefix(id.Name, "Scope"):
+							add(n.Values[i])
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	return keys
+}
+
+func TestCatalogsHaveEveryMessage(t *testing.T) {
+	keys := messageKeys(t)
+	if len(keys) < 100 {
+		t.Fatalf("Only found %d messages in the code", len(keys))
+	}
+
+	files, err := filepath.Glob("locales/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &Catalog{}
+		if err := json.Unmarshal(data, c); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if len(c.Messages) == 0 {
+			continue
+		}
+
+		for key, pos := range keys {
+			if _, ok := c.Messages[key]; !ok {
+				t.Errorf("%s is missing %q from %s", file, key, pos)
+			}
+		}
+	}
+}
+
+func TestHasLanguage(t *testing.T) {
+	defer useCatalogs(map[string]*Catalog{
+		"es": {Name: "Español", Messages: map[string]Translation{"Moves for %s": {"other": "Movimientos de %s"}}},
+		"de": {Name: "Deutsch", Pokemon: map[string]string{"Charizard": "Glurak"}},
+	})()
+
+	// de only has names, so its messages fall back to English
+	for lang, want := range map[string]bool{"en": true, "en-us": true, "es": true, "es-mx": true, "de": true, "de-at": true, "xx": false} {
+		if got := HasLanguage(lang); got != want {
+			t.Errorf("HasLanguage(%s) = %v, want %v", lang, got, want)
+		}
+	}
+	if langs := Languages(); strings.Join(langs, ",") != "en,de,es" {
+		t.Errorf("Languages() = %v, want [en de es]", langs)
+	}
+	if got := Translate("de", "Moves for %s"); got != "Moves for %s" {
+		t.Errorf("Translate(de) = %q, want the English message", got)
+	}
+}
+
+func TestNamesOnlyLanguagePref(t *testing.T) {
+	defer useTempGuildFile(t)()
+	defer useCatalogs(map[string]*Catalog{"de": {Name: "Deutsch", Pokemon: map[string]string{"Charizard": "Glurak"}}})()
+
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{ChannelID: "c", Author: &discordgo.User{ID: "names-only-user"}}}
+	b := &botResponse{m: m}
+	defer GetUser("names-only-user").SetPref("", "")
+
+	// Same as !prefs set language de
+	if _, err := b.setPref("language", "de", ScopeUser); err != nil {
+		t.Fatalf("Setting language to de = %v", err)
+	}
+	if got := b.PokemonName(&pogo.Pokemon{Name: "Charizard"}); got != "Glurak" {
+		t.Errorf("PokemonName(Charizard) = %s, want Glurak", got)
+	}
+}
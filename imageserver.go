@@ -0,0 +1,73 @@
+package haynesbot
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// startImageServer serves the image folder at /img/ and raid charts at /chart/raidchart/{pokemon}.{png|svg}
+func startImageServer(addr string) {
+	mux := http.NewServeMux()
+	if ImageServer != "" {
+		mux.Handle("/img/", http.StripPrefix("/img/", http.FileServer(http.Dir(ImageServer))))
+	}
+	mux.HandleFunc("/chart/raidchart/", raidChartHandler)
+
+	log.Println("Image server listening on", addr)
+	go func() {
+		log.Println(http.ListenAndServe(addr, mux))
+	}()
+}
+
+// raidChartHandler serves raid charts from the render cache. Add ?palette= with a palette name to choose the colors.
+func raidChartHandler(w http.ResponseWriter, r *http.Request) {
+	file := path.Base(r.URL.Path)
+	format := strings.TrimPrefix(path.Ext(file), ".")
+	if !IsTableFormat(format) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Only named palettes, since every custom palette would be a new chart to draw and keep.
+	// The request is checked before the pokemon so a bad palette is always a bad request.
+	palette := DefaultPalette
+	if spec := r.URL.Query().Get("palette"); spec != "" {
+		var ok bool
+		if palette, ok = palettes[strings.ToLower(spec)]; !ok {
+			http.Error(w, "Unknown palette", http.StatusBadRequest)
+			return
+		}
+	}
+
+	p, err := GetPokemon(strings.TrimSuffix(file, path.Ext(file)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ivList, _ := p.GetRaidCPChart()
+	table := RaidChartTable(p, ivList, palette, DefaultLanguage)
+	key := RenderKey("RAIDCHART-"+p.ID, table, palette, format)
+	data, err := renderer.Render(key, table, format)
+	if be, ok := err.(*botError); ok && be.err == ERR_RENDER_BUSY {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		log.Println("Unable to render table:", err)
+		http.Error(w, "Unable to draw chart", http.StatusInternalServerError)
+		return
+	}
+
+	if format == FormatSVG {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeContent(w, r, key, time.Time{}, bytes.NewReader(data))
+}
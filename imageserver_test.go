@@ -0,0 +1,27 @@
+package haynesbot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRaidChartHandlerPalette(t *testing.T) {
+	// The palette and format are checked before the pokemon, so these don't need pogo data
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/chart/raidchart/notapokemon.png?" + url.Values{"palette": {"custom:0:#000000,100:#ffffff"}}.Encode(), http.StatusBadRequest},
+		{"/chart/raidchart/notapokemon.svg?palette=nope", http.StatusBadRequest},
+		{"/chart/raidchart/notapokemon.gif?palette=viridis", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		raidChartHandler(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.want {
+			t.Errorf("GET %s got %d, want %d", test.path, w.Code, test.want)
+		}
+	}
+}
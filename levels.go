@@ -0,0 +1,302 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/haynesherway/pogo"
+)
+
+// Level limits. Pokemon can be powered up to MaxLevel, and a best buddy is boosted one level more.
+const (
+	MinLevel       = 1.0
+	MaxLevel       = 50.0
+	BestBuddyBoost = 1.0
+	MaxBuddyLevel  = MaxLevel + BestBuddyBoost
+)
+
+// MinCP is the lowest CP a pokemon can have, however weak
+const MinCP = 10
+
+// Level errors
+var (
+	ERR_LEVEL_INVALID    = errors.New("Invalid level or stardust")
+	ERR_LEVEL_BEST_BUDDY = errors.New("Best buddies can't be above level 51")
+)
+
+// cpMultipliers are the CP multipliers of whole levels, starting at level 1
+var cpMultipliers = []float64{
+	0.094, 0.16639787, 0.21573247, 0.25572005, 0.29024988, 0.3210876, 0.34921268, 0.37523559, 0.39956728, 0.42250001,
+	0.44310755, 0.46279839, 0.48168495, 0.49985844, 0.51739395, 0.53435433, 0.55079269, 0.56675452, 0.58227891, 0.59740001,
+	0.61215729, 0.62656713, 0.64065295, 0.65443563, 0.667934, 0.68116492, 0.69414365, 0.70688421, 0.71939909, 0.7317,
+	0.73776948, 0.74378943, 0.74976104, 0.75568551, 0.76156384, 0.76739717, 0.7731865, 0.77893275, 0.784637, 0.79030001,
+	0.79530001, 0.8003, 0.8053, 0.81029999, 0.81529999, 0.82029999, 0.82529999, 0.83029999, 0.83529999, 0.84029999,
+	0.84529999,
+}
+
+// stardustCosts are the stardust costs to power up from each whole level and the half level after it, starting at level 1
+var stardustCosts = []int{
+	200, 200, 400, 400, 600, 600, 800, 800, 1000, 1000,
+	1300, 1300, 1600, 1600, 1900, 1900, 2200, 2200, 2500, 2500,
+	3000, 3000, 3500, 3500, 4000, 4000, 4500, 4500, 5000, 5000,
+	6000, 6000, 7000, 7000, 8000, 8000, 9000, 9000, 10000, 10000,
+	11000, 11000, 12000, 12000, 13000, 13000, 14000, 14000, 15000,
+}
+
+// candyCosts are the candy costs to power up from each whole level and the half level after it, up to level 39.5
+var candyCosts = []int{
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	3, 3, 3, 3, 3, 4, 4, 4, 4, 4,
+	6, 6, 8, 8, 10, 10, 12, 12, 15,
+}
+
+// xlCandyCosts are the XL candy costs to power up from each whole level and the half level after it, starting at level 40
+var xlCandyCosts = []int{10, 10, 12, 12, 15, 15, 17, 17, 20, 20}
+
+// PowerUpCost is what it takes to power up a pokemon
+type PowerUpCost struct {
+	Stardust, Candy, XLCandy int
+}
+
+// Add adds the cost of another power up
+func (c PowerUpCost) Add(other PowerUpCost) PowerUpCost {
+	return PowerUpCost{c.Stardust + other.Stardust, c.Candy + other.Candy, c.XLCandy + other.XLCandy}
+}
+
+// CostModifiers change what power ups cost. Shadow pokemon cost 20% more,
+// purified ones 10% less, and lucky ones half the stardust.
+type CostModifiers struct {
+	Shadow, Purified, Lucky bool
+}
+
+// Apply changes the cost of one power up, rounding up like the game does
+func (m CostModifiers) Apply(cost PowerUpCost) PowerUpCost {
+	scale := func(n int, percent int) int {
+		return (n*percent + 99) / 100
+	}
+	for _, c := range []*int{&cost.Stardust, &cost.Candy, &cost.XLCandy} {
+		if m.Shadow {
+			*c = scale(*c, 120)
+		} else if m.Purified {
+			*c = scale(*c, 90)
+		}
+	}
+	if m.Lucky {
+		cost.Stardust = scale(cost.Stardust, 50)
+	}
+	return cost
+}
+
+// LevelCost returns the cost to power up from a level to the next half level
+func LevelCost(level float64, mods CostModifiers) PowerUpCost {
+	cost := PowerUpCost{Stardust: StardustCost(level)}
+	if cost.Stardust == 0 {
+		return cost
+	}
+	if i := int(level) - 1; i < len(candyCosts) {
+		cost.Candy = candyCosts[i]
+	} else {
+		cost.XLCandy = xlCandyCosts[i-len(candyCosts)]
+	}
+	return mods.Apply(cost)
+}
+
+// CostToLevel returns the cost to power up from a level to a higher one
+func CostToLevel(from float64, to float64, mods CostModifiers) PowerUpCost {
+	cost := PowerUpCost{}
+	for level := from; level < to; level += 0.5 {
+		cost = cost.Add(LevelCost(level, mods))
+	}
+	return cost
+}
+
+// ValidLevel checks if a level is a whole or half level a pokemon can be at
+func ValidLevel(level float64) bool {
+	return level >= MinLevel && level <= MaxBuddyLevel && level*2 == math.Floor(level*2)
+}
+
+// CPMultiplier returns the CP multiplier of a level. Half levels are between the levels around them.
+func CPMultiplier(level float64) float64 {
+	if !ValidLevel(level) {
+		return 0
+	}
+	i := int(level) - 1
+	if level == math.Floor(level) {
+		return cpMultipliers[i]
+	}
+	return math.Sqrt((cpMultipliers[i]*cpMultipliers[i] + cpMultipliers[i+1]*cpMultipliers[i+1]) / 2)
+}
+
+// StardustCost returns the stardust it takes to power up from a level, 0 when it can't be powered up
+func StardustCost(level float64) int {
+	if !ValidLevel(level) || level >= MaxLevel {
+		return 0
+	}
+	return stardustCosts[int(level)-1]
+}
+
+// StardustLevels returns the levels that take an amount of stardust to power up
+func StardustLevels(stardust int) []float64 {
+	levels := []float64{}
+	for level := MinLevel; level < MaxLevel; level += 0.5 {
+		if StardustCost(level) == stardust {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// IsStardustCost checks if an amount of stardust is a power up cost
+func IsStardustCost(stardust int) bool {
+	for _, cost := range stardustCosts {
+		if cost == stardust {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLevel reads a level, like 40 or 40.5, or a power up cost in stardust, like 10000.
+// Whole and half levels can't be confused with power up costs, so there's no cutoff between them.
+func ParseLevel(s string) (float64, int, error) {
+	if val, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(s), "l"), 64); err == nil && ValidLevel(val) {
+		return val, 0, nil
+	}
+	if dust, err := strconv.Atoi(s); err == nil && IsStardustCost(dust) {
+		return 0, dust, nil
+	}
+	return 0, 0, &botError{ERR_LEVEL_INVALID, s}
+}
+
+// isBestBuddy checks if an argument is the best buddy flag
+func isBestBuddy(s string) bool {
+	switch strings.ToLower(s) {
+	case "bb", "buddy", "bestbuddy":
+		return true
+	}
+	return false
+}
+
+// splitBestBuddy removes the best buddy flag from command arguments
+func splitBestBuddy(args []string) ([]string, bool) {
+	rest := []string{}
+	buddy := false
+	for _, arg := range args {
+		if isBestBuddy(arg) {
+			buddy = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, buddy
+}
+
+// CalcCP returns the CP of a pokemon with base stats at a level with IVs
+func CalcCP(stats pogo.Stats, level float64, attack, defense, stamina int) int {
+	cpm := CPMultiplier(level)
+	cp := int(float64(stats.BaseAttack+attack) * math.Sqrt(float64(stats.BaseDefense+defense)) *
+		math.Sqrt(float64(stats.BaseStamina+stamina)) * cpm * cpm / 10)
+	if cp < MinCP {
+		return MinCP
+	}
+	return cp
+}
+
+// CalcHP returns the HP of a pokemon with base stats at a level with a stamina IV
+func CalcHP(stats pogo.Stats, level float64, stamina int) int {
+	hp := int(float64(stats.BaseStamina+stamina) * CPMultiplier(level))
+	if hp < 10 {
+		return 10
+	}
+	return hp
+}
+
+// IVPercent returns the IVs as a percentage of perfect
+func IVPercent(attack, defense, stamina int) int {
+	return int(math.Round(float64(attack+defense+stamina) * 100 / 45))
+}
+
+// IVMatch is an IV combination and level that gives the CP and HP a pokemon has
+type IVMatch struct {
+	Level                    float64
+	Attack, Defense, Stamina int
+	Percent                  int
+}
+
+// levelRange returns every level from min to max
+func levelRange(min float64, max float64) []float64 {
+	levels := []float64{}
+	for level := min; level <= max; level += 0.5 {
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// FindIVs returns the IV combinations at the levels that give a CP and HP, best first.
+// best lists the stats the appraisal says are highest, like "ad", or is empty.
+// Levels are the levels the pokemon was powered up to, best buddies are found one level higher.
+func FindIVs(stats pogo.Stats, cp int, hp int, levels []float64, best string, buddy bool) []IVMatch {
+	boost := 0.0
+	if buddy {
+		boost = BestBuddyBoost
+	}
+
+	matches := []IVMatch{}
+	for _, level := range levels {
+		for a := 0; a <= 15; a++ {
+			for d := 0; d <= 15; d++ {
+				for s := 0; s <= 15; s++ {
+					if CalcHP(stats, level+boost, s) != hp || CalcCP(stats, level+boost, a, d, s) != cp {
+						continue
+					}
+					if !matchesAppraisal(a, d, s, best) {
+						continue
+					}
+					matches = append(matches, IVMatch{level, a, d, s, IVPercent(a, d, s)})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Percent > matches[j].Percent })
+	return matches
+}
+
+// matchesAppraisal checks that the stats in best are the highest, and the others are lower
+func matchesAppraisal(attack, defense, stamina int, best string) bool {
+	if best == "" {
+		return true
+	}
+	highest := attack
+	if defense > highest {
+		highest = defense
+	}
+	if stamina > highest {
+		highest = stamina
+	}
+	for stat, iv := range map[string]int{"a": attack, "d": defense, "s": stamina} {
+		if (iv == highest) != strings.Contains(best, stat) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLevel prints a level without a trailing .0
+func formatLevel(level float64) string {
+	return strconv.FormatFloat(level, 'f', -1, 64)
+}
+
+// IVChart prints IV combinations as a text chart with a header line
+func IVChart(matches []IVMatch) string {
+	lines := []string{fmt.Sprintf("%-5s %-4s %2s %2s %2s", "Lvl", "IV%", "A", "D", "S")}
+	for _, m := range matches {
+		lines = append(lines, fmt.Sprintf("%-5s %-4s %2d %2d %2d", formatLevel(m.Level), strconv.Itoa(m.Percent)+"%", m.Attack, m.Defense, m.Stamina))
+	}
+	return strings.Join(lines, "\n")
+}
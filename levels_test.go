@@ -0,0 +1,79 @@
+package haynesbot
+
+import (
+	"testing"
+
+	"github.com/haynesherway/pogo"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in       string
+		level    float64
+		stardust int
+		ok       bool
+	}{
+		{"40", 40, 0, true},
+		{"48.5", 48.5, 0, true},
+		{"51", 51, 0, true},
+		{"41.3", 0, 0, false},
+		{"52", 0, 0, false},
+		{"200", 0, 200, true},
+		{"10000", 0, 10000, true},
+		{"15000", 0, 15000, true},
+		{"4200", 0, 0, false},
+	}
+	for _, test := range tests {
+		level, stardust, err := ParseLevel(test.in)
+		if (err == nil) != test.ok || level != test.level || stardust != test.stardust {
+			t.Errorf("ParseLevel(%s) = %v, %d, %v", test.in, level, stardust, err)
+		}
+	}
+
+	if levels := StardustLevels(10000); len(levels) != 4 || levels[0] != 39 || levels[3] != 40.5 {
+		t.Errorf("StardustLevels(10000) = %v", levels)
+	}
+}
+
+func TestCalcCP(t *testing.T) {
+	mewtwo := pogo.Stats{BaseAttack: 300, BaseDefense: 182, BaseStamina: 214}
+	tests := []struct {
+		level float64
+		want  int
+	}{
+		{40, 4178},
+		{50, 4724},
+		{51, 4780},
+	}
+	for _, test := range tests {
+		if got := CalcCP(mewtwo, test.level, 15, 15, 15); got != test.want {
+			t.Errorf("CalcCP(mewtwo, %v) = %d, want %d", test.level, got, test.want)
+		}
+	}
+
+	matches := FindIVs(mewtwo, 4178, 180, []float64{40}, "", false)
+	if len(matches) == 0 || matches[0].Percent != 100 {
+		t.Errorf("FindIVs didn't find the perfect mewtwo: %v", matches)
+	}
+}
+
+func TestCostToLevel(t *testing.T) {
+	tests := []struct {
+		from, to float64
+		mods     CostModifiers
+		want     PowerUpCost
+	}{
+		{1, 40, CostModifiers{}, PowerUpCost{270000, 304, 0}},
+		{40, 50, CostModifiers{}, PowerUpCost{250000, 0, 296}},
+		{39.5, 40.5, CostModifiers{}, PowerUpCost{20000, 15, 10}},
+		{50, 50, CostModifiers{}, PowerUpCost{}},
+		{1, 1.5, CostModifiers{Shadow: true}, PowerUpCost{240, 2, 0}},
+		{11, 11.5, CostModifiers{Purified: true}, PowerUpCost{1170, 2, 0}},
+		{40, 40.5, CostModifiers{Shadow: true, Lucky: true}, PowerUpCost{6000, 0, 12}},
+	}
+	for _, test := range tests {
+		if got := CostToLevel(test.from, test.to, test.mods); got != test.want {
+			t.Errorf("CostToLevel(%v, %v) = %+v, want %+v", test.from, test.to, got, test.want)
+		}
+	}
+}
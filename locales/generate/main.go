@@ -0,0 +1,285 @@
+// Command generate fills the Pokemon, Moves and Types names of the translation files in locales
+// from the text files of the game, like the i18n_german.json files PokeMiners extracts from the apk.
+//
+//	go run ./locales/generate -texts path/to/Texts/Latest\ APK/JSON
+//
+// Names, Messages and anything else already in a translation file are kept.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gameLanguages are the names the game gives the text files of each language the bot has a translation file for
+var gameLanguages = map[string]string{
+	"de": "german",
+	"es": "spanish",
+	"fr": "french",
+	"ja": "japanese",
+	"pt": "brazilianportuguese",
+}
+
+// types are in the order the game lists them
+var types = []string{
+	"normal", "fighting", "flying", "poison", "ground", "rock", "bug", "ghost", "steel",
+	"fire", "water", "grass", "electric", "psychic", "ice", "dragon", "dark", "fairy",
+}
+
+// sections are written after these, in this order
+var sections = []string{"Pokemon", "Moves", "Types"}
+
+func main() {
+	texts := flag.String("texts", "", "folder with the i18n_{language}.json text files of the game")
+	pokemon := flag.String("pokemon", "bot/pokemonNames.csv", "csv of pokedex numbers and the names pogo uses")
+	locales := flag.String("locales", "locales", "folder with the translation files")
+	flag.Parse()
+
+	if *texts == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dex, err := readPokedex(*pokemon)
+	if err != nil {
+		log.Fatal(err)
+	}
+	english, err := readTexts(filepath.Join(*texts, "i18n_english.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for lang, game := range gameLanguages {
+		translated, err := readTexts(filepath.Join(*texts, "i18n_"+game+".json"))
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		file := filepath.Join(*locales, lang+".json")
+		if err := update(file, names(dex, english, translated)); err != nil {
+			log.Println(file, err)
+		}
+	}
+}
+
+// name is an English name and its translation
+type name struct {
+	english, translated string
+}
+
+// names returns the translated names of pokemon, moves and types, keyed by section
+func names(dex map[int]string, english, translated map[string]string) map[string][]name {
+	found := map[string][]name{}
+
+	numbers := []int{}
+	for n := range dex {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	seen := map[string]bool{}
+	for _, n := range numbers {
+		// pogo calls both Nidoran just Nidoran, so the first one names them
+		if t, ok := translated[fmt.Sprintf("pokemon_name_%04d", n)]; ok && !seen[dex[n]] {
+			seen[dex[n]] = true
+			found["Pokemon"] = append(found["Pokemon"], name{dex[n], t})
+		}
+	}
+
+	moves := []string{}
+	for key := range english {
+		if strings.HasPrefix(key, "move_name_") {
+			moves = append(moves, key)
+		}
+	}
+	sort.Strings(moves)
+	seen = map[string]bool{}
+	for _, key := range moves {
+		// Weather Ball and Techno Blast have a move for every type with the same name
+		if t, ok := translated[key]; ok && !seen[english[key]] {
+			seen[english[key]] = true
+			found["Moves"] = append(found["Moves"], name{english[key], t})
+		}
+	}
+
+	for _, typ := range types {
+		key := "pokemon_type_" + typ
+		if t, ok := translated[key]; ok {
+			found["Types"] = append(found["Types"], name{english[key], t})
+		}
+	}
+	return found
+}
+
+// readPokedex reads the name pogo uses for each pokedex number. Forms share the number, and the shortest name is the pokemon.
+func readPokedex(file string) (map[int]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	dex := map[int]string{}
+	for _, row := range rows {
+		n, err := strconv.Atoi(row[0])
+		if err != nil || len(row) < 2 {
+			continue
+		}
+		if current, ok := dex[n]; !ok || len(row[1]) < len(current) {
+			dex[n] = row[1]
+		}
+	}
+	return dex, nil
+}
+
+// readTexts reads a text file of the game, a list of keys each followed by its text
+func readTexts(file string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := struct {
+		Data []string `json:"data"`
+	}{}
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return nil, err
+	}
+
+	m := map[string]string{}
+	for i := 0; i+1 < len(texts.Data); i += 2 {
+		m[texts.Data[i]] = strings.TrimSpace(texts.Data[i+1])
+	}
+	return m, nil
+}
+
+// update replaces the names in a translation file, keeping everything else where it was
+func update(file string, found map[string][]name) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	order, err := keyOrder(data)
+	if err != nil {
+		return err
+	}
+	current := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+
+	for _, section := range sections {
+		if len(found[section]) == 0 {
+			continue
+		}
+		if _, ok := current[section]; !ok {
+			order = append(order, section)
+		}
+		current[section] = object(found[section])
+	}
+
+	// Names go after everything else so the messages stay on top
+	keys := []string{}
+	for _, key := range order {
+		if !isSection(key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, section := range sections {
+		if _, ok := current[section]; ok {
+			keys = append(keys, section)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("{\n")
+	for i, key := range keys {
+		// Everything but the names is written as it was read, blank lines and all
+		value := bytes.NewBuffer(current[key])
+		if _, ok := found[key]; ok {
+			value = &bytes.Buffer{}
+			if err := json.Indent(value, current[key], "    ", "    "); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "    %s: %s", quote(key), value.Bytes())
+		if i < len(keys)-1 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("}\n")
+	return ioutil.WriteFile(file, out.Bytes(), 0644)
+}
+
+// keyOrder returns the keys of a json object in the order they are in the file
+func keyOrder(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// object returns names as a json object in their order
+func object(names []name) json.RawMessage {
+	out := &bytes.Buffer{}
+	out.WriteString("{")
+	for i, n := range names {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		fmt.Fprintf(out, "%s:%s", quote(n.english), quote(n.translated))
+	}
+	out.WriteString("}")
+	return out.Bytes()
+}
+
+// quote returns s as a json string, leaving <, > and & as they are like the rest of the files
+func quote(s string) string {
+	out := &bytes.Buffer{}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func isSection(key string) bool {
+	for _, section := range sections {
+		if key == section {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package haynesbot
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/haynesherway/pogo"
+)
+
+// Kinds of names that can be translated
+const (
+	KindPokemon = "pokemon"
+	KindMove    = "move"
+	KindType    = "type"
+)
+
+// nameFolder makes names comparable no matter how they're typed, so "Flabébé", "flabebe" and "Mr. Mime" match
+var nameFolder = strings.NewReplacer(
+	" ", "", "-", "", ".", "", "'", "", "’", "", ":", "", "_", "",
+	"à", "a", "á", "a", "â", "a", "ä", "a", "ã", "a",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ò", "o", "ó", "o", "ô", "o", "ö", "o", "õ", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ß", "ss", "♀", "f", "♂", "m",
+)
+
+// foldName returns the form of a name used to look it up
+func foldName(name string) string {
+	return nameFolder.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// names maps translated pokemon, move and type names in every language to the English name pogo knows
+type names struct {
+	sync.RWMutex
+	english map[string]map[string]string
+}
+
+var nameIndex = &names{english: make(map[string]map[string]string)}
+
+// add indexes the names a catalog translates
+func (n *names) add(c *Catalog) {
+	n.Lock()
+	defer n.Unlock()
+
+	for kind, translated := range catalogNames(c) {
+		if n.english[kind] == nil {
+			n.english[kind] = make(map[string]string)
+		}
+		for english, name := range translated {
+			n.english[kind][foldName(name)] = english
+		}
+	}
+}
+
+// English returns the English name for a name in any language, or the name itself when it isn't translated
+func (n *names) English(kind string, name string) string {
+	n.RLock()
+	defer n.RUnlock()
+
+	if english, ok := n.english[kind][foldName(name)]; ok {
+		return english
+	}
+	return name
+}
+
+// catalogNames returns the names a catalog translates by kind
+func catalogNames(c *Catalog) map[string]map[string]string {
+	return map[string]map[string]string{
+		KindPokemon: c.Pokemon,
+		KindMove:    c.Moves,
+		KindType:    c.Types,
+	}
+}
+
+// LocalName returns the name of a pokemon, move or type in a language, or the English name when it isn't translated
+func LocalName(lang string, kind string, english string) string {
+	if c := findCatalog(lang); c != nil {
+		translated := catalogNames(c)[kind]
+		if name, ok := translated[english]; ok {
+			return name
+		}
+		for key, name := range translated {
+			if strings.EqualFold(key, english) {
+				return name
+			}
+		}
+	}
+	return english
+}
+
+// LocalizeNames translates every English name of a kind in text, like the list of moves or types pogo prints
+func LocalizeNames(lang string, kind string, text string) string {
+	c := findCatalog(lang)
+	if c == nil || len(catalogNames(c)[kind]) == 0 {
+		return text
+	}
+
+	// Longer names go first so Mewtwo isn't translated as Mew + two. Names that are the same in both languages are left alone.
+	english := []string{}
+	for name, translated := range catalogNames(c)[kind] {
+		if translated != name {
+			english = append(english, name)
+		}
+	}
+	sort.Slice(english, func(i, j int) bool { return len(english[i]) > len(english[j]) })
+
+	pairs := []string{}
+	for _, name := range english {
+		pairs = append(pairs, name, catalogNames(c)[kind][name])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// pokedex has the pokedex number of every pokemon and form, by ID and by English name
+var pokedex = struct {
+	sync.RWMutex
+	numbers map[string]int
+}{numbers: make(map[string]int)}
+
+// LoadPokedex reads pokedex numbers from a csv of number, English name and ID, like pokemonNames.csv next to the bot
+func LoadPokedex(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	pokedex.Lock()
+	defer pokedex.Unlock()
+	for _, row := range rows {
+		n, err := strconv.Atoi(row[0])
+		if err != nil || len(row) < 3 {
+			continue
+		}
+		pokedex.numbers[strings.ToLower(row[1])] = n
+		pokedex.numbers[strings.ToLower(row[2])] = n
+	}
+	return nil
+}
+
+// PokedexNumber returns the pokedex number of a pokemon
+func PokedexNumber(p *pogo.Pokemon) (int, bool) {
+	pokedex.RLock()
+	defer pokedex.RUnlock()
+
+	if n, ok := pokedex.numbers[strings.ToLower(p.ID)]; ok {
+		return n, true
+	}
+	n, ok := pokedex.numbers[strings.ToLower(p.Name)]
+	return n, ok
+}
+
+// GetPokemon finds a pokemon by its name in any language
+func GetPokemon(name string) (*pogo.Pokemon, error) {
+	return pogo.GetPokemon(strings.ToLower(nameIndex.English(KindPokemon, name)))
+}
+
+// GetType finds a type by its name in any language
+func GetType(name string) (*pogo.Type, error) {
+	return pogo.GetType(strings.ToLower(nameIndex.English(KindType, name)))
+}
+
+// PokemonName returns the name of a pokemon in the language of the user
+func (b *botResponse) PokemonName(p *pogo.Pokemon) string {
+	return LocalName(b.lang(), KindPokemon, p.Name)
+}
+
+// Names translates the names of a kind in text into the language of the user
+func (b *botResponse) Names(kind string, text string) string {
+	return LocalizeNames(b.lang(), kind, text)
+}
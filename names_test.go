@@ -0,0 +1,83 @@
+package haynesbot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestNameIndex(t *testing.T) {
+	defer useCatalogs(map[string]*Catalog{"de": {Name: "Deutsch",
+		Pokemon: map[string]string{"Charizard": "Glurak", "Mewtwo": "Mewtu", "Flabebe": "Flabébé"},
+		Types:   map[string]string{"Fire": "Feuer", "Flying": "Flug"},
+	}})()
+
+	tests := []struct {
+		kind, name, want string
+	}{
+		{KindPokemon, "glurak", "Charizard"},
+		{KindPokemon, "GLURAK", "Charizard"},
+		{KindPokemon, "flabebe", "Flabebe"},
+		{KindPokemon, "pikachu", "pikachu"},
+		{KindType, "feuer", "Fire"},
+	}
+	for _, test := range tests {
+		if got := nameIndex.English(test.kind, test.name); got != test.want {
+			t.Errorf("English(%s, %s) = %s, want %s", test.kind, test.name, got, test.want)
+		}
+	}
+
+	if got := LocalName("de-at", KindPokemon, "charizard"); got != "Glurak" {
+		t.Errorf("LocalName = %s, want Glurak", got)
+	}
+	if got := LocalizeNames("de", KindType, "Fire, Flying"); got != "Feuer, Flug" {
+		t.Errorf("LocalizeNames = %s, want Feuer, Flug", got)
+	}
+}
+
+func TestUseCatalogsRestoresNames(t *testing.T) {
+	restore := useCatalogs(map[string]*Catalog{"de": {Pokemon: map[string]string{"Charizard": "Glurak"}}})
+	if got := nameIndex.English(KindPokemon, "glurak"); got != "Charizard" {
+		t.Fatalf("English(glurak) = %s, want Charizard", got)
+	}
+	restore()
+
+	if findCatalog("de") != nil {
+		t.Error("de catalog is still there")
+	}
+	if got := nameIndex.English(KindPokemon, "glurak"); got != "glurak" {
+		t.Errorf("English(glurak) = %s after restoring, want glurak", got)
+	}
+}
+
+// TestLocaleNames checks the translation files name every type and that no translated name sends an English name to another pokemon
+func TestLocaleNames(t *testing.T) {
+	files, _ := filepath.Glob("locales/*.json")
+	index := &names{english: make(map[string]map[string]string)}
+	english := map[string]bool{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &Catalog{}
+		if err := json.Unmarshal(data, c); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if len(c.Types) != 18 || len(c.Pokemon) == 0 || len(c.Moves) == 0 {
+			t.Errorf("%s has %d types, %d pokemon and %d moves", file, len(c.Types), len(c.Pokemon), len(c.Moves))
+		}
+
+		index.add(c)
+		for name := range c.Pokemon {
+			english[name] = true
+		}
+	}
+
+	for name := range english {
+		if got := index.English(KindPokemon, name); got != name {
+			t.Errorf("%s finds %s", name, got)
+		}
+	}
+}
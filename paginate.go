@@ -0,0 +1,207 @@
+package haynesbot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Pagination settings
+const (
+	PageRows         = 40
+	PaginatorTimeout = 2 * time.Minute
+)
+
+// Reactions used to flip pages
+const (
+	emojiPrevPage = "◀"
+	emojiNextPage = "▶"
+)
+
+// paginator lets the user who ran a command flip through the pages of a long reply.
+// It keeps each page's own footer and adds the page number when the page is shown.
+type paginator struct {
+	sync.Mutex
+	pages     []*discordgo.MessageEmbed
+	footers   []string
+	lang      string
+	page      int
+	userID    string
+	channelID string
+	messageID string
+	timer     *time.Timer
+}
+
+// newPaginator creates a paginator for pages, with the footers they have after the theme is applied
+func newPaginator(pages []*discordgo.MessageEmbed, lang string) *paginator {
+	p := &paginator{pages: pages, lang: lang}
+	for _, page := range pages {
+		footer := ""
+		if page.Footer != nil {
+			footer = page.Footer.Text
+		}
+		p.footers = append(p.footers, footer)
+	}
+	return p
+}
+
+// render returns a copy of a page with its footer and page number
+func (p *paginator) render(i int) *discordgo.MessageEmbed {
+	page := *p.pages[i]
+	footer := &discordgo.MessageEmbedFooter{}
+	if page.Footer != nil {
+		*footer = *page.Footer
+	}
+	footer.Text = fmt.Sprintf(Translate(p.lang, "Page %d/%d"), i+1, len(p.pages))
+	if p.footers[i] != "" {
+		footer.Text = truncate(p.footers[i]+" • "+footer.Text, EmbedLimitFooter)
+	}
+	page.Footer = footer
+	return &page
+}
+
+var paginators = struct {
+	sync.Mutex
+	m map[string]*paginator
+}{m: make(map[string]*paginator)}
+
+// SplitRows splits rows into pages of at most size rows
+func SplitRows(rows []string, size int) [][]string {
+	pages := [][]string{}
+	for len(rows) > size {
+		pages = append(pages, rows[:size])
+		rows = rows[size:]
+	}
+	if len(rows) > 0 {
+		pages = append(pages, rows)
+	}
+	return pages
+}
+
+// splitPages splits pages that are over the embed limits into more pages and applies the theme to each of them
+func (b *botResponse) splitPages(pages []*discordgo.MessageEmbed) []*discordgo.MessageEmbed {
+	split := []*discordgo.MessageEmbed{}
+	for _, page := range pages {
+		split = append(split, (&Embed{MessageEmbed: page}).Split()...)
+	}
+	for _, page := range split {
+		b.theme().Apply(page)
+	}
+	return split
+}
+
+// PrintPagesToDiscord prints the first page to discord and adds reactions to flip through the rest
+func (b *botResponse) PrintPagesToDiscord(pages []*discordgo.MessageEmbed) {
+	if len(pages) == 0 {
+		return
+	}
+	pages = b.splitPages(pages)
+	if len(pages) == 1 {
+		b.PrintEmbedToDiscord(pages[0])
+		return
+	}
+	if b.compact() {
+		page := pages[0]
+		page.Footer = &discordgo.MessageEmbedFooter{Text: b.T("Page 1/%d. Use !prefs set format full to see every page.", len(pages))}
+		b.PrintEmbedToDiscord(page)
+		return
+	}
+
+	p := newPaginator(pages, b.lang())
+
+	m := b.sendEmbed(p.render(0))
+	if m == nil {
+		return
+	}
+	p.userID = b.m.Author.ID
+	p.channelID = m.ChannelID
+	p.messageID = m.ID
+
+	_ = b.s.MessageReactionAdd(m.ChannelID, m.ID, emojiPrevPage)
+	_ = b.s.MessageReactionAdd(m.ChannelID, m.ID, emojiNextPage)
+
+	s := b.s
+	p.timer = time.AfterFunc(PaginatorTimeout, func() {
+		p.close(s)
+	})
+
+	paginators.Lock()
+	if old, ok := paginators.m[m.ID]; ok {
+		old.timer.Stop()
+	}
+	paginators.m[m.ID] = p
+	paginators.Unlock()
+}
+
+// PrintEveryPageToDiscord prints every page of a long reply at once instead of one page with controls
+func (b *botResponse) PrintEveryPageToDiscord(pages []*discordgo.MessageEmbed) {
+	pages = b.splitPages(pages)
+	if len(pages) == 1 {
+		b.PrintEmbedToDiscord(pages[0])
+		return
+	}
+
+	p := newPaginator(pages, b.lang())
+	for i := range pages {
+		b.PrintEmbedToDiscord(p.render(i))
+	}
+}
+
+// flip moves the paginator by delta pages and shows the new page
+func (p *paginator) flip(s *discordgo.Session, delta int) {
+	p.Lock()
+	defer p.Unlock()
+
+	page := p.page + delta
+	if page < 0 || page >= len(p.pages) {
+		return
+	}
+	p.page = page
+	p.timer.Reset(PaginatorTimeout)
+
+	_, _ = s.ChannelMessageEditEmbed(p.channelID, p.messageID, p.render(p.page))
+}
+
+// close stops the paginator and removes the page controls
+func (p *paginator) close(s *discordgo.Session) {
+	paginators.Lock()
+	if paginators.m[p.messageID] == p {
+		delete(paginators.m, p.messageID)
+	}
+	paginators.Unlock()
+
+	if err := s.MessageReactionsRemoveAll(p.channelID, p.messageID); err != nil {
+		// Without Manage Messages the bot can only remove its own reactions
+		_ = s.MessageReactionRemove(p.channelID, p.messageID, emojiPrevPage, "@me")
+		_ = s.MessageReactionRemove(p.channelID, p.messageID, emojiNextPage, "@me")
+	}
+}
+
+// paginationHandler flips pages when the user who ran the command reacts
+func paginationHandler(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if r.UserID == BotID {
+		return
+	}
+
+	paginators.Lock()
+	p, ok := paginators.m[r.MessageID]
+	paginators.Unlock()
+	if !ok || r.UserID != p.userID {
+		return
+	}
+
+	switch strings.TrimSuffix(r.Emoji.Name, "\ufe0f") {
+	case emojiPrevPage:
+		p.flip(s, -1)
+	case emojiNextPage:
+		p.flip(s, 1)
+	default:
+		return
+	}
+
+	// Remove the reaction so the same button can be used again
+	_ = s.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji.Name, r.UserID)
+}
@@ -0,0 +1,65 @@
+package haynesbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSplitRows(t *testing.T) {
+	rows := make([]string, 95)
+	pages := SplitRows(rows, 40)
+
+	if len(pages) != 3 {
+		t.Fatalf("SplitRows gave %d pages, want 3", len(pages))
+	}
+	if len(pages[0]) != 40 || len(pages[2]) != 15 {
+		t.Errorf("unexpected page sizes: %d, %d, %d", len(pages[0]), len(pages[1]), len(pages[2]))
+	}
+
+	if len(SplitRows(nil, 40)) != 0 {
+		t.Error("no rows should give no pages")
+	}
+}
+
+func TestPaginatorRender(t *testing.T) {
+	pages := []*discordgo.MessageEmbed{
+		{Title: "one", Footer: &discordgo.MessageEmbedFooter{Text: "Team Rocket", IconURL: "icon"}},
+		{Title: "two"},
+	}
+	p := newPaginator(pages, DefaultLanguage)
+
+	// Rendering twice must not stack page numbers
+	p.render(0)
+	first := p.render(0)
+	if first.Footer.Text != "Team Rocket • Page 1/2" || first.Footer.IconURL != "icon" {
+		t.Errorf("First page footer = %+v", first.Footer)
+	}
+	if second := p.render(1); second.Footer.Text != "Page 2/2" {
+		t.Errorf("Second page footer = %q", second.Footer.Text)
+	}
+	if pages[0].Footer.Text != "Team Rocket" {
+		t.Errorf("Rendering changed the page footer to %q", pages[0].Footer.Text)
+	}
+}
+
+func TestSplitPages(t *testing.T) {
+	b := &botResponse{}
+	big := NewEmbed().SetTitle("big")
+	for i := 0; i < 30; i++ {
+		big.AddField("row", strings.Repeat("x", 200))
+	}
+	pages := b.splitPages([]*discordgo.MessageEmbed{big.MessageEmbed, {Title: "small"}})
+
+	if len(pages) != 3 {
+		t.Fatalf("splitPages gave %d pages, want 3", len(pages))
+	}
+	p := newPaginator(pages, DefaultLanguage)
+	for i := range pages {
+		page := &Embed{MessageEmbed: p.render(i)}
+		if len(page.Fields) > EmbedLimitField || page.Length() > EmbedLimit {
+			t.Errorf("Page %d has %d fields and %d characters", i+1, len(page.Fields), page.Length())
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Palette errors
+var (
+	ERR_PALETTE_COMMAND = errors.New("Choose the colors for charts using !palette {default|viridis|contrast|mono} or !palette custom {percent:#color,...}. Add 'channel' or 'server' to set it for everyone.")
+	ERR_INVALID_PALETTE = errors.New("Custom palettes need at least two stops like 0:#440154,90:#21918c,100:#fde725")
+)
+
+// Palette colors rows of IV charts by their IV percentage
+type Palette interface {
+	Color(percent int) color.Color
+	Key() string
+}
+
+// PaletteStop is a color at a percentage in a gradient palette
+type PaletteStop struct {
+	Percent int
+	Color   color.RGBA
+}
+
+// percentPalette is the original red to green haynesbot palette
+type percentPalette struct{}
+
+func (percentPalette) Color(percent int) color.Color { return PercentColor(percent) }
+func (percentPalette) Key() string                   { return "default" }
+
+// gradientPalette blends between color stops
+type gradientPalette struct {
+	name  string
+	stops []PaletteStop
+}
+
+// Color returns the color for a percentage, blending the stops around it
+func (g gradientPalette) Color(percent int) color.Color {
+	stops := g.stops
+	if percent <= stops[0].Percent {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		if percent > stops[i].Percent {
+			continue
+		}
+		lo, hi := stops[i-1], stops[i]
+		t := float64(percent-lo.Percent) / float64(hi.Percent-lo.Percent)
+		blend := func(a, b uint8) uint8 {
+			return uint8(float64(a) + t*(float64(b)-float64(a)) + 0.5)
+		}
+		return color.RGBA{blend(lo.Color.R, hi.Color.R), blend(lo.Color.G, hi.Color.G), blend(lo.Color.B, hi.Color.B), 255}
+	}
+	return stops[len(stops)-1].Color
+}
+
+// Key identifies the palette in image names, custom palettes use a hash of their stops
+func (g gradientPalette) Key() string {
+	if g.name != "custom" {
+		return g.name
+	}
+	h := fnv.New32a()
+	for _, s := range g.stops {
+		fmt.Fprintf(h, "%d:%02x%02x%02x,", s.Percent, s.Color.R, s.Color.G, s.Color.B)
+	}
+	return fmt.Sprintf("custom-%08x", h.Sum32())
+}
+
+// Built in palettes. The stops are packed near the top since raid charts only show high IVs.
+var palettes = map[string]Palette{
+	"default": percentPalette{},
+	"viridis": gradientPalette{"viridis", []PaletteStop{
+		{0, rgb(0x440154)}, {84, rgb(0x3B528B)}, {90, rgb(0x21918C)}, {95, rgb(0x5EC962)}, {100, rgb(0xFDE725)},
+	}},
+	"contrast": gradientPalette{"contrast", []PaletteStop{
+		{0, rgb(0xD55E00)}, {88, rgb(0xE69F00)}, {93, rgb(0xF0E442)}, {97, rgb(0x56B4E9)}, {100, rgb(0x0072B2)},
+	}},
+	"mono": gradientPalette{"mono", []PaletteStop{
+		{0, rgb(0x303030)}, {80, rgb(0x505050)}, {100, rgb(0xFFFFFF)},
+	}},
+}
+
+// DefaultPalette is used when neither the user nor the guild chose a palette
+var DefaultPalette Palette = percentPalette{}
+
+// PaletteNames returns the names of the built in palettes
+func PaletteNames() []string {
+	names := []string{}
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParsePalette reads a palette name, or a custom palette like "custom:0:#440154,100:#fde725"
+func ParsePalette(spec string) (Palette, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if p, ok := palettes[spec]; ok {
+		return p, nil
+	}
+
+	if !strings.HasPrefix(spec, "custom:") {
+		return nil, &botError{ERR_PALETTE_COMMAND, ""}
+	}
+
+	stops := []PaletteStop{}
+	for _, s := range strings.Split(strings.TrimPrefix(spec, "custom:"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(s), ":", 2)
+		if len(parts) != 2 {
+			return nil, &botError{ERR_INVALID_PALETTE, ""}
+		}
+		percent, err := strconv.Atoi(strings.TrimSuffix(parts[0], "%"))
+		if err != nil || percent < 0 || percent > 100 {
+			return nil, &botError{ERR_INVALID_PALETTE, ""}
+		}
+		clr, err := ParseColor(parts[1])
+		if err != nil {
+			return nil, &botError{ERR_INVALID_PALETTE, ""}
+		}
+		stops = append(stops, PaletteStop{percent, rgb(clr)})
+	}
+
+	sort.Slice(stops, func(i, j int) bool { return stops[i].Percent < stops[j].Percent })
+	if len(stops) < 2 || stops[0].Percent == stops[len(stops)-1].Percent {
+		return nil, &botError{ERR_INVALID_PALETTE, ""}
+	}
+	for i := 1; i < len(stops); i++ {
+		if stops[i].Percent == stops[i-1].Percent {
+			return nil, &botError{ERR_INVALID_PALETTE, ""}
+		}
+	}
+
+	return gradientPalette{"custom", stops}, nil
+}
+
+// TextColor returns black or white, whichever is easier to read on the background
+func TextColor(bg color.Color) color.Color {
+	r, g, b, _ := bg.RGBA()
+	luminance := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+	if luminance < 128 {
+		return WHITE
+	}
+	return BLACK
+}
+
+func rgb(clr int) color.RGBA {
+	return color.RGBA{uint8(clr >> 16), uint8(clr >> 8), uint8(clr), 255}
+}
+
+// palette returns the palette from the preferences of the user
+func (b *botResponse) palette() Palette {
+	if p, err := ParsePalette(b.Pref("palette")); err == nil {
+		return p
+	}
+	return DefaultPalette
+}
+
+// SetPaletteCommand lets users pick the colors for their charts, or owners for the whole server
+func SetPaletteCommand(b *botResponse) error {
+	if len(b.fields) < 2 {
+		b.PrintToDiscord(b.T("Your charts use the %s palette. Palettes: %s, or custom {percent:#color,...}",
+			b.palette().Key(), strings.Join(PaletteNames(), ", ")))
+		return nil
+	}
+
+	args, scope := splitScope(b.fields[1:])
+	if len(args) == 0 {
+		return &botError{ERR_PALETTE_COMMAND, ""}
+	}
+
+	msg, err := b.setPref("palette", strings.Join(args, " "), scope)
+	if err != nil {
+		return err
+	}
+	b.PrintToDiscord(msg)
+	return nil
+}
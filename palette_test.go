@@ -0,0 +1,41 @@
+package haynesbot
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParsePalette(t *testing.T) {
+	for _, name := range PaletteNames() {
+		p, err := ParsePalette(name)
+		if err != nil || p.Key() != name {
+			t.Errorf("ParsePalette(%q) = %v, %v", name, p, err)
+		}
+	}
+
+	p, err := ParsePalette("custom:100:#ffffff,0:#000000")
+	if err != nil {
+		t.Fatalf("ParsePalette(custom) failed: %v", err)
+	}
+	if got := p.Color(50); got != (color.RGBA{128, 128, 128, 255}) {
+		t.Errorf("custom palette at 50%% = %v, want mid gray", got)
+	}
+	if same, _ := ParsePalette("custom:0:#000000,100:#FFFFFF"); same.Key() != p.Key() {
+		t.Errorf("same stops gave different keys: %s, %s", same.Key(), p.Key())
+	}
+
+	for _, spec := range []string{"rainbow", "custom:50:#ffffff", "custom:0:#000000,0:#ffffff", "custom:0:black,100:#ffffff"} {
+		if _, err := ParsePalette(spec); err == nil {
+			t.Errorf("ParsePalette(%q) should fail", spec)
+		}
+	}
+}
+
+func TestTextColor(t *testing.T) {
+	if TextColor(rgb(0x440154)) != WHITE {
+		t.Error("dark backgrounds should get white text")
+	}
+	if TextColor(rgb(0xFDE725)) != BLACK {
+		t.Error("light backgrounds should get black text")
+	}
+}
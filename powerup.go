@@ -0,0 +1,181 @@
+package haynesbot
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/haynesherway/pogo"
+)
+
+// Power up errors
+var (
+	ERR_POWERUP_COMMAND = errors.New("Power up command needs to be formatted like this: !powerup {pokemon} {level} {target level} or !powerup {pokemon} {level|cp} {attack iv} {defense iv} {stamina iv} {target level|target cp} {'shadow'|'purified'} {'lucky'}")
+	ERR_POWERUP_CP      = errors.New("No level gives that CP with those IVs")
+	ERR_POWERUP_TARGET  = errors.New("The target has to be above where the pokemon is now")
+)
+
+// splitCostModifiers removes the shadow, purified and lucky flags from command arguments
+func splitCostModifiers(args []string) ([]string, CostModifiers) {
+	rest := []string{}
+	mods := CostModifiers{}
+	for _, arg := range args {
+		switch strings.ToLower(arg) {
+		case "shadow":
+			mods.Shadow = true
+		case "purified":
+			mods.Purified = true
+		case "lucky":
+			mods.Lucky = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, mods
+}
+
+// parsePowerUpLevel reads a level a pokemon can be powered up to, which can be written with l in front
+func parsePowerUpLevel(s string) (float64, bool) {
+	level, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(s), "l"), 64)
+	if err != nil || !ValidLevel(level) || level > MaxLevel {
+		return 0, false
+	}
+	return level, true
+}
+
+// parsePowerUpCP reads a CP, which can be written with cp in front
+func parsePowerUpCP(s string) (int, bool) {
+	cp, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(s), "cp"))
+	return cp, err == nil && cp >= MinCP
+}
+
+// powerUpFrom returns the level a power up starts at. With IVs, a number the pokemon has as its CP at some level
+// is read as that CP, since the game shows CP and not levels. Anything else has to be a level.
+func powerUpFrom(p *pogo.Pokemon, arg string, withIVs bool, attack, defense, stamina int) (float64, error) {
+	level, isLevel := parsePowerUpLevel(arg)
+	if cp, isCP := parsePowerUpCP(arg); isCP && withIVs {
+		if from, ok := levelForCP(p, cp, attack, defense, stamina); ok {
+			return from, nil
+		}
+		if !isLevel {
+			return 0, &botError{ERR_POWERUP_CP, strconv.Itoa(cp)}
+		}
+	}
+	if !isLevel {
+		return 0, &botError{ERR_LEVEL_INVALID, arg}
+	}
+	return level, nil
+}
+
+// powerUpTo returns the level a power up ends at, and the CP it's for if the target is a CP. With IVs, a number
+// above the CP the pokemon has now is read as a target CP, and anything else has to be a level.
+func powerUpTo(p *pogo.Pokemon, arg string, from float64, withIVs bool, attack, defense, stamina int) (to float64, cp int, reachable bool, err error) {
+	level, isLevel := parsePowerUpLevel(arg)
+	if cp, isCP := parsePowerUpCP(arg); isCP && withIVs && (!isLevel || cp > CalcCP(p.Stats, from, attack, defense, stamina)) {
+		to, reachable := levelToReachCP(p, from, cp, attack, defense, stamina)
+		return to, cp, reachable, nil
+	}
+	if !isLevel {
+		return 0, 0, false, &botError{ERR_LEVEL_INVALID, arg}
+	}
+	return level, 0, true, nil
+}
+
+// levelForCP returns the lowest level a pokemon with IVs has a CP at
+func levelForCP(p *pogo.Pokemon, cp int, attack, defense, stamina int) (float64, bool) {
+	for _, level := range levelRange(MinLevel, MaxLevel) {
+		if CalcCP(p.Stats, level, attack, defense, stamina) == cp {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// levelToReachCP returns the lowest level from a level on where a pokemon with IVs has at least a CP
+func levelToReachCP(p *pogo.Pokemon, from float64, cp int, attack, defense, stamina int) (float64, bool) {
+	for _, level := range levelRange(from, MaxLevel) {
+		if CalcCP(p.Stats, level, attack, defense, stamina) >= cp {
+			return level, true
+		}
+	}
+	return MaxLevel, false
+}
+
+// PrintPowerUpToDiscord prints the stardust and candy it takes to power up a pokemon to a level or CP
+func PrintPowerUpToDiscord(b *botResponse) error {
+	if len(b.fields) < 4 {
+		return &botError{ERR_POWERUP_COMMAND, ""}
+	}
+	args, mods := splitCostModifiers(b.fields[2:])
+	if (len(args) != 2 && len(args) != 5) || (mods.Shadow && mods.Purified) {
+		return &botError{ERR_POWERUP_COMMAND, ""}
+	}
+
+	p, err := GetPokemon(strings.ToLower(b.fields[1]))
+	if err != nil {
+		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
+	}
+	if p.Stats.BaseAttack == 0 {
+		return &botError{ERR_NO_STATS, b.PokemonName(p)}
+	}
+
+	withIVs := len(args) == 5
+	attack, defense, stamina := 0, 0, 0
+	if withIVs {
+		var ok bool
+		if attack, defense, stamina, ok = parseIVs(args[1:4]); !ok {
+			return &botError{ERR_POWERUP_COMMAND, ""}
+		}
+	}
+
+	from, err := powerUpFrom(p, args[0], withIVs, attack, defense, stamina)
+	if err != nil {
+		return err
+	}
+	to, targetCP, reachable, err := powerUpTo(p, args[len(args)-1], from, withIVs, attack, defense, stamina)
+	if err != nil {
+		return err
+	}
+	if to <= from {
+		return &botError{ERR_POWERUP_TARGET, ""}
+	}
+
+	describe := func(level float64) string {
+		if withIVs {
+			return b.T("Level %v, CP %d", level, CalcCP(p.Stats, level, attack, defense, stamina))
+		}
+		return b.T("Level %v", level)
+	}
+
+	cost := CostToLevel(from, to, mods)
+	emb := b.NewEmbed().
+		SetColorRole(ColorResult).
+		SetTitle(b.T("Power up %s", b.PokemonName(p))).
+		AddField(b.T("From"), describe(from)).
+		AddField(b.T("To"), describe(to)).
+		AddField(b.T("Cost"), b.T("%d stardust\n%d candy\n%d XL candy", cost.Stardust, cost.Candy, cost.XLCandy)).
+		SetThumbnail(p.API.Sprites.Front)
+
+	if targetCP > 0 && reachable {
+		emb.SetDescription(b.T("CP %d is reached at level %v.", targetCP, to))
+	} else if targetCP > 0 {
+		emb.SetDescription(b.T("CP %d can't be reached, the most is CP %d at level %v.", targetCP, CalcCP(p.Stats, MaxLevel, attack, defense, stamina), MaxLevel))
+	}
+
+	notes := []string{}
+	if mods.Shadow {
+		notes = append(notes, b.T("Shadow: 20% more stardust and candy"))
+	}
+	if mods.Purified {
+		notes = append(notes, b.T("Purified: 10% less stardust and candy"))
+	}
+	if mods.Lucky {
+		notes = append(notes, b.T("Lucky: half the stardust"))
+	}
+	if len(notes) > 0 {
+		emb.SetFooter(strings.Join(notes, ", "))
+	}
+
+	b.PrintEmbedToDiscord(emb.MessageEmbed)
+	return nil
+}
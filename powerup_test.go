@@ -0,0 +1,77 @@
+package haynesbot
+
+import (
+	"testing"
+
+	"github.com/haynesherway/pogo"
+)
+
+func TestPowerUpCostModifiers(t *testing.T) {
+	tests := []struct {
+		mods CostModifiers
+		want PowerUpCost
+	}{
+		{CostModifiers{}, PowerUpCost{75000, 66, 0}},
+		{CostModifiers{Shadow: true}, PowerUpCost{90000, 86, 0}},
+		{CostModifiers{Purified: true}, PowerUpCost{67500, 66, 0}},
+		{CostModifiers{Lucky: true}, PowerUpCost{37500, 66, 0}},
+		{CostModifiers{Purified: true, Lucky: true}, PowerUpCost{33750, 66, 0}},
+	}
+	for _, test := range tests {
+		if got := CostToLevel(20, 30, test.mods); got != test.want {
+			t.Errorf("CostToLevel(20, 30, %+v) = %+v, want %+v", test.mods, got, test.want)
+		}
+	}
+
+	b := &botResponse{fields: []string{"!powerup", "metagross", "30", "40", "shadow", "purified"}}
+	if err := PrintPowerUpToDiscord(b); err == nil || err.(*botError).err != ERR_POWERUP_COMMAND {
+		t.Errorf("Shadow and purified together = %v, want %v", err, ERR_POWERUP_COMMAND)
+	}
+}
+
+func TestPowerUpLevelOrCP(t *testing.T) {
+	magikarp := &pogo.Pokemon{Stats: pogo.Stats{BaseAttack: 29, BaseDefense: 85, BaseStamina: 85}}
+	mewtwo := &pogo.Pokemon{Stats: pogo.Stats{BaseAttack: 300, BaseDefense: 182, BaseStamina: 214}}
+
+	from := []struct {
+		p       *pogo.Pokemon
+		arg     string
+		withIVs bool
+		want    float64
+	}{
+		{magikarp, "37", true, 5},
+		{magikarp, "l37", true, 37},
+		{magikarp, "37", false, 37},
+		{mewtwo, "30", true, 30},
+		{mewtwo, "cp4178", true, 40},
+	}
+	for _, test := range from {
+		if got, err := powerUpFrom(test.p, test.arg, test.withIVs, 15, 15, 15); err != nil || got != test.want {
+			t.Errorf("powerUpFrom(%s) = %v, %v, want %v", test.arg, got, err, test.want)
+		}
+	}
+	if _, err := powerUpFrom(mewtwo, "cp5000", true, 15, 15, 15); err == nil || err.(*botError).err != ERR_POWERUP_CP {
+		t.Errorf("powerUpFrom(cp5000) = %v, want %v", err, ERR_POWERUP_CP)
+	}
+
+	to := []struct {
+		p         *pogo.Pokemon
+		arg       string
+		from      float64
+		want      float64
+		cp        int
+		reachable bool
+	}{
+		{magikarp, "45", 5, 6, 45, true},
+		{magikarp, "l45", 5, 45, 0, true},
+		{magikarp, "20", 5, 20, 0, true},
+		{mewtwo, "4178", 20, 40, 4178, true},
+		{mewtwo, "9999", 20, MaxLevel, 9999, false},
+	}
+	for _, test := range to {
+		got, cp, reachable, err := powerUpTo(test.p, test.arg, test.from, true, 15, 15, 15)
+		if err != nil || got != test.want || cp != test.cp || reachable != test.reachable {
+			t.Errorf("powerUpTo(%s from %v) = %v, CP %d, %v, %v, want %v, CP %d, %v", test.arg, test.from, got, cp, reachable, err, test.want, test.cp, test.reachable)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package haynesbot
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// MaxPrefixLength is the longest prefix a guild can set
+const MaxPrefixLength = 5
+
+// Prefix errors
+var (
+	ERR_PREFIXES_COMMAND = errors.New("Manage the prefixes for your guild using !prefix add {prefix}, !prefix remove {prefix}, !prefix list or !prefix ignorecase {on|off}")
+	ERR_INVALID_PREFIX   = errors.New("Prefixes can't contain spaces and can be at most 5 characters.")
+	ERR_PREFIX_EXISTS    = errors.New("That prefix is already set.")
+	ERR_PREFIX_MISSING   = errors.New("That prefix isn't set.")
+	ERR_LAST_PREFIX      = errors.New("You can't remove the only prefix. Add another one first.")
+)
+
+// Prefixes returns every prefix the bot answers to in a guild
+func (guild *Guild) Prefixes() []string {
+	if len(guild.Settings.Prefixes) == 0 {
+		return []string{guild.Settings.BotPrefix}
+	}
+	return guild.Settings.Prefixes
+}
+
+// MatchPrefix returns the prefix a message starts with in a guild
+func (guild *Guild) MatchPrefix(content string) (string, bool) {
+	return matchPrefix(content, guild.Prefixes(), guild.Settings.PrefixIgnoreCase)
+}
+
+// AddPrefix adds another prefix the bot answers to in a guild
+func (guild *Guild) AddPrefix(pre string) error {
+	if !IsValidPrefix(pre) {
+		return &botError{ERR_INVALID_PREFIX, pre}
+	}
+
+	prefixes := guild.Prefixes()
+	for _, p := range prefixes {
+		if p == pre {
+			return &botError{ERR_PREFIX_EXISTS, pre}
+		}
+	}
+
+	guild.Settings.Prefixes = append(prefixes, pre)
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// RemovePrefix removes a prefix from a guild, keeping at least one
+func (guild *Guild) RemovePrefix(pre string) error {
+	prefixes := guild.Prefixes()
+	for i, p := range prefixes {
+		if p != pre {
+			continue
+		}
+		if len(prefixes) == 1 {
+			return &botError{ERR_LAST_PREFIX, ""}
+		}
+
+		guild.Settings.Prefixes = append(prefixes[:i:i], prefixes[i+1:]...)
+		guild.Settings.BotPrefix = guild.Settings.Prefixes[0]
+		guildSettings.add(guild).save(config.GuildFile)
+		return nil
+	}
+
+	return &botError{ERR_PREFIX_MISSING, pre}
+}
+
+// SetPrefixIgnoreCase sets whether prefixes like hb! also match HB!
+func (guild *Guild) SetPrefixIgnoreCase(ignore bool) error {
+	guild.Settings.PrefixIgnoreCase = ignore
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// IsValidPrefix returns true if a string can be used as a prefix
+func IsValidPrefix(pre string) bool {
+	if pre == "" || len([]rune(pre)) > MaxPrefixLength {
+		return false
+	}
+	return !strings.ContainsAny(pre, " \t\n")
+}
+
+// matchPrefix returns the longest prefix a message starts with, as it was typed in the message
+func matchPrefix(content string, prefixes []string, ignoreCase bool) (string, bool) {
+	sorted := append([]string{}, prefixes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	for _, p := range sorted {
+		if p == "" || len(content) < len(p) {
+			continue
+		}
+		if content[:len(p)] == p || (ignoreCase && strings.EqualFold(content[:len(p)], p)) {
+			return content[:len(p)], true
+		}
+	}
+	return "", false
+}
+
+// prefixesText lists the prefixes of a guild, and whether they ignore case
+func (b *botResponse) prefixesText(guild *Guild) string {
+	prefixes := strings.Join(guild.Prefixes(), "  ")
+	if guild.Settings.PrefixIgnoreCase {
+		prefixes += "\n" + b.T("(ignoring case)")
+	}
+	return prefixes
+}
+
+// addPrefixesField lists the prefixes of a guild on an embed when it has more than one, since commands are only shown with the first
+func (b *botResponse) addPrefixesField(e *Embed, guild *Guild) *Embed {
+	if guild == nil || len(guild.Prefixes()) < 2 {
+		return e
+	}
+	return e.AddField(b.T("Server Prefixes"), b.prefixesText(guild))
+}
+
+// PrefixCommand allows the server owner to manage the prefixes for a guild
+func PrefixCommand(b *botResponse) error {
+	guild := b.guild
+	if guild == nil {
+		return &botError{ERR_NO_GUILD, ""}
+	}
+
+	if !guild.IsOwner(b.m.Author) {
+		return &botError{ERR_NOT_OWNER, ""}
+	}
+
+	if len(b.fields) < 2 {
+		return &botError{ERR_PREFIXES_COMMAND, ""}
+	}
+
+	switch strings.ToLower(b.fields[1]) {
+	case "list":
+		emb := b.NewEmbed().
+			SetColorRole(ColorInfo).
+			AddField(b.T("Server Prefixes"), b.prefixesText(guild)).MessageEmbed
+		b.PrintEmbedToDiscord(emb)
+		return nil
+	case "add":
+		if len(b.fields) < 3 {
+			return &botError{ERR_PREFIXES_COMMAND, ""}
+		}
+		if err := guild.AddPrefix(b.fields[2]); err != nil {
+			return err
+		}
+		b.PrintToDiscord(b.T("Haynesbot now also answers to %s", b.fields[2]))
+	case "remove":
+		if len(b.fields) < 3 {
+			return &botError{ERR_PREFIXES_COMMAND, ""}
+		}
+		if err := guild.RemovePrefix(b.fields[2]); err != nil {
+			return err
+		}
+		b.PrintToDiscord(b.T("Haynesbot no longer answers to %s", b.fields[2]))
+	case "ignorecase":
+		if len(b.fields) < 3 {
+			return &botError{ERR_PREFIXES_COMMAND, ""}
+		}
+		var ignore bool
+		switch strings.ToLower(b.fields[2]) {
+		case "on":
+			ignore = true
+		case "off":
+			ignore = false
+		default:
+			return &botError{ERR_PREFIXES_COMMAND, ""}
+		}
+		guild.SetPrefixIgnoreCase(ignore)
+		if ignore {
+			b.PrintToDiscord(b.T("Prefixes now ignore case."))
+		} else {
+			b.PrintToDiscord(b.T("Prefixes are now case sensitive."))
+		}
+	default:
+		return &botError{ERR_PREFIXES_COMMAND, ""}
+	}
+
+	return nil
+}
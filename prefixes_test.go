@@ -0,0 +1,57 @@
+package haynesbot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestMatchPrefix(t *testing.T) {
+	prefixes := []string{"!", "hb!", "?"}
+
+	tests := []struct {
+		content    string
+		ignoreCase bool
+		want       string
+		ok         bool
+	}{
+		{"!iv machamp 2526 143 33", false, "!", true},
+		{"hb!raidiv groudon", false, "hb!", true},
+		{"HB!raidiv groudon", false, "", false},
+		{"HB!raidiv groudon", true, "HB!", true},
+		{"raidiv groudon!", false, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := matchPrefix(tt.content, prefixes, tt.ignoreCase)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("matchPrefix(%q, %v) = %q, %v, want %q, %v", tt.content, tt.ignoreCase, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCustomCommandNamePrefixes(t *testing.T) {
+	guild := &Guild{Settings: GuildSetting{BotPrefix: "!", Prefixes: []string{"!", "hb!"}, PrefixIgnoreCase: true}}
+
+	for _, name := range []string{"rules", "!rules", "hb!rules", "HB!Rules"} {
+		if got := customCommandName(name, guild); got != "rules" {
+			t.Errorf("customCommandName(%s) = %s, want rules", name, got)
+		}
+	}
+}
+
+func TestPrefixIgnoreCaseValue(t *testing.T) {
+	defer useTempGuildFile(t)()
+
+	owner := &discordgo.User{ID: "owner"}
+	guild := &Guild{Guild: &discordgo.Guild{ID: "g", OwnerID: owner.ID}, Settings: GuildSetting{ID: "g", BotPrefix: "!"}}
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{ChannelID: "c", Author: owner}}
+	b := &botResponse{m: m, guild: guild, fields: []string{"!prefix", "ignorecase", "maybe"}}
+
+	if err := PrefixCommand(b); err == nil || err.(*botError).err != ERR_PREFIXES_COMMAND {
+		t.Errorf("!prefix ignorecase maybe = %v, want %v", err, ERR_PREFIXES_COMMAND)
+	}
+	if guild.Settings.PrefixIgnoreCase {
+		t.Error("!prefix ignorecase maybe changed the setting")
+	}
+}
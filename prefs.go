@@ -0,0 +1,360 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Preference errors
+var (
+	ERR_PREFS_COMMAND  = errors.New("Manage your preferences using !prefs, !prefs get {name}, !prefs set {name} {value} or !prefs reset {name|all}. Server owners can add 'channel' or 'server' to set them for everyone.")
+	ERR_PREF_UNKNOWN   = errors.New("Unknown preference")
+	ERR_PREF_INVALID   = errors.New("Invalid value for preference")
+	ERR_PREF_USER_ONLY = errors.New("Preference can only be set for yourself")
+)
+
+// Preference scopes, from most to least specific
+const (
+	ScopeUser    = "user"
+	ScopeChannel = "channel"
+	ScopeServer  = "server"
+	ScopeGlobal  = "global"
+)
+
+// Preference is a setting users can choose for themselves, and owners for a channel or the whole server
+type Preference struct {
+	Name     string
+	Info     string
+	Values   []string
+	UserOnly bool
+
+	// Check validates a value and returns it the way it's stored, preferences with Values don't need it
+	Check func(string) (string, error)
+}
+
+var preferences = []Preference{
+	{"images", "Show charts as images or as text", []string{"on", "off"}, false, nil},
+	{"textonly", "Only get text replies, with images described instead", []string{"on", "off"}, true, nil},
+	{"format", "Full replies, or compact ones without sprites that only show the first page of long charts", []string{"full", "compact"}, false, nil},
+	{"palette", "Colors for IV charts: " + strings.Join(PaletteNames(), ", ") + " or custom {percent:#color,...}", nil, false, checkPalettePref},
+	{"league", "Default PvP league", LeagueNames(), false, nil},
+	{"timezone", "Timezone for dates, like America/New_York", nil, false, checkTimezonePref},
+	{"language", "Language for replies, like en, es or pt", nil, false, checkLanguagePref},
+}
+
+// defaultPrefs are used when no user, channel, guild or config sets a preference
+var defaultPrefs = map[string]string{
+	"images":   "off",
+	"textonly": "off",
+	"format":   "full",
+	"palette":  "default",
+	"league":   "great",
+	"timezone": "UTC",
+	"language": "en",
+}
+
+// GetPreference finds a preference by name
+func GetPreference(name string) (Preference, bool) {
+	name = strings.ToLower(name)
+	for _, pref := range preferences {
+		if pref.Name == name {
+			return pref, true
+		}
+	}
+	return Preference{}, false
+}
+
+// Parse validates a value for the preference
+func (pref Preference) Parse(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if pref.Check != nil {
+		return pref.Check(value)
+	}
+
+	value = strings.ToLower(value)
+	for _, v := range pref.Values {
+		if v == value {
+			return v, nil
+		}
+	}
+	return "", &botError{ERR_PREF_INVALID, pref.Name}
+}
+
+func checkPalettePref(value string) (string, error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return "", &botError{ERR_PREF_INVALID, "palette"}
+	}
+
+	spec := fields[0]
+	if spec == "custom" {
+		spec = "custom:" + strings.Join(fields[1:], "")
+	}
+	if _, err := ParsePalette(spec); err != nil {
+		return "", err
+	}
+	return spec, nil
+}
+
+func checkTimezonePref(value string) (string, error) {
+	if _, err := time.LoadLocation(value); err != nil || value == "" || strings.EqualFold(value, "local") {
+		return "", &botError{ERR_PREF_INVALID, "timezone"}
+	}
+	return value, nil
+}
+
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+func checkLanguagePref(value string) (string, error) {
+	value = strings.ToLower(strings.Replace(value, "_", "-", -1))
+	if !languageTag.MatchString(value) || !HasLanguage(value) {
+		return "", &botError{ERR_PREF_INVALID, "language"}
+	}
+	return value, nil
+}
+
+// globalPref returns the preference from the config, or else the default
+func globalPref(name string) string {
+	if name == "images" && UseImages {
+		return "on"
+	}
+	if config != nil {
+		if value, ok := config.Prefs[name]; ok {
+			return value
+		}
+	}
+	return defaultPrefs[name]
+}
+
+// prefSource returns the value of a preference for the user in this channel and where it was set,
+// looking at the user, then the channel, then the guild, then the config
+func (b *botResponse) prefSource(name string) (string, string) {
+	if user, ok := LookupUser(b.m.Author.ID); ok {
+		if value := user.Pref(name); value != "" {
+			return value, ScopeUser
+		}
+	}
+
+	if b.guild != nil {
+		if value := b.guild.ChannelPref(b.m.ChannelID, name); value != "" {
+			return value, ScopeChannel
+		}
+		if value := b.guild.Pref(name); value != "" {
+			return value, ScopeServer
+		}
+	}
+
+	return globalPref(name), ScopeGlobal
+}
+
+// Pref returns the value of a preference for the user in this channel
+func (b *botResponse) Pref(name string) string {
+	value, _ := b.prefSource(name)
+	return value
+}
+
+// compact returns true if the user wants compact replies
+func (b *botResponse) compact() bool {
+	return b.Pref("format") == "compact"
+}
+
+// location returns the timezone of the user
+func (b *botResponse) location() *time.Location {
+	loc, err := time.LoadLocation(b.Pref("timezone"))
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Pref returns a preference the guild set
+func (guild *Guild) Pref(name string) string {
+	return guild.Settings.Prefs[name]
+}
+
+// ChannelPref returns a preference the guild set for a channel
+func (guild *Guild) ChannelPref(channelID string, name string) string {
+	return guild.Settings.ChannelPrefs[channelID][name]
+}
+
+// SetPref sets a preference for the guild, or for a channel when channelID isn't empty.
+// An empty value resets the preference, and an empty name resets all of them.
+func (guild *Guild) SetPref(channelID string, name string, value string) error {
+	prefs := guild.Settings.Prefs
+	if channelID != "" {
+		prefs = guild.Settings.ChannelPrefs[channelID]
+	}
+
+	switch {
+	case name == "":
+		prefs = nil
+	case value == "":
+		delete(prefs, name)
+	default:
+		if prefs == nil {
+			prefs = make(map[string]string)
+		}
+		prefs[name] = value
+	}
+
+	if channelID == "" {
+		guild.Settings.Prefs = prefs
+	} else if len(prefs) == 0 {
+		delete(guild.Settings.ChannelPrefs, channelID)
+	} else {
+		if guild.Settings.ChannelPrefs == nil {
+			guild.Settings.ChannelPrefs = make(map[string]map[string]string)
+		}
+		guild.Settings.ChannelPrefs[channelID] = prefs
+	}
+
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// setPref validates and stores a preference in a scope, returning a message saying what changed.
+// An empty value resets the preference.
+func (b *botResponse) setPref(name string, value string, scope string) (string, error) {
+	pref, ok := GetPreference(name)
+	if !ok {
+		return "", &botError{ERR_PREF_UNKNOWN, name}
+	}
+
+	if value != "" {
+		var err error
+		if value, err = pref.Parse(value); err != nil {
+			return "", err
+		}
+	}
+
+	if scope != ScopeUser && pref.UserOnly {
+		return "", &botError{ERR_PREF_USER_ONLY, pref.Name}
+	}
+
+	var where string
+	switch scope {
+	case ScopeChannel, ScopeServer:
+		guild, err := b.ownerGuild()
+		if err != nil {
+			return "", err
+		}
+		channelID := ""
+		where = b.T("this server")
+		if scope == ScopeChannel {
+			channelID = b.m.ChannelID
+			where = b.T("this channel")
+		}
+		guild.SetPref(channelID, pref.Name, value)
+	default:
+		GetUser(b.m.Author.ID).SetPref(pref.Name, value)
+		where = b.T("you")
+	}
+
+	if value == "" {
+		return b.T("Reset %s for %s.", pref.Name, where), nil
+	}
+	return b.T("Set %s to %s for %s.", pref.Name, value, where), nil
+}
+
+// splitScope removes a trailing 'channel' or 'server' from command arguments
+func splitScope(args []string) ([]string, string) {
+	if len(args) > 0 {
+		switch strings.ToLower(args[len(args)-1]) {
+		case ScopeChannel:
+			return args[:len(args)-1], ScopeChannel
+		case ScopeServer:
+			return args[:len(args)-1], ScopeServer
+		}
+	}
+	return args, ScopeUser
+}
+
+// PrefsCommand lets users get, set and reset their preferences, and owners set them for a channel or server
+func PrefsCommand(b *botResponse) error {
+	if len(b.fields) < 2 {
+		return b.printPrefs()
+	}
+
+	args, scope := splitScope(b.fields[2:])
+	switch strings.ToLower(b.fields[1]) {
+	case "get":
+		if len(args) < 1 {
+			return &botError{ERR_PREFS_COMMAND, ""}
+		}
+		pref, ok := GetPreference(args[0])
+		if !ok {
+			return &botError{ERR_PREF_UNKNOWN, args[0]}
+		}
+		value, source := b.prefSource(pref.Name)
+		b.PrintToDiscord(b.T("%s is %s (set by %s). %s", pref.Name, value, b.T(source), b.T(pref.Info)))
+	case "set":
+		if len(args) < 2 {
+			return &botError{ERR_PREFS_COMMAND, ""}
+		}
+		msg, err := b.setPref(args[0], strings.Join(args[1:], " "), scope)
+		if err != nil {
+			return err
+		}
+		b.PrintToDiscord(msg)
+	case "reset":
+		if len(args) < 1 {
+			return &botError{ERR_PREFS_COMMAND, ""}
+		}
+		if strings.ToLower(args[0]) != "all" {
+			msg, err := b.setPref(args[0], "", scope)
+			if err != nil {
+				return err
+			}
+			b.PrintToDiscord(msg)
+			return nil
+		}
+
+		switch scope {
+		case ScopeChannel, ScopeServer:
+			guild, err := b.ownerGuild()
+			if err != nil {
+				return err
+			}
+			if scope == ScopeChannel {
+				guild.SetPref(b.m.ChannelID, "", "")
+				b.PrintToDiscord(b.T("Reset all preferences for this channel."))
+			} else {
+				guild.SetPref("", "", "")
+				b.PrintToDiscord(b.T("Reset all preferences for this server."))
+			}
+		default:
+			GetUser(b.m.Author.ID).SetPref("", "")
+			b.PrintToDiscord(b.T("Reset all your preferences."))
+		}
+	default:
+		return &botError{ERR_PREFS_COMMAND, ""}
+	}
+	return nil
+}
+
+// printPrefs prints every preference with its value and where it was set
+func (b *botResponse) printPrefs() error {
+	names := []string{}
+	for _, pref := range preferences {
+		names = append(names, pref.Name)
+	}
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		value, source := b.prefSource(name)
+		lines = append(lines, fmt.Sprintf("%-9s %-12s (%s)", name, value, b.T(source)))
+	}
+
+	emb := b.NewEmbed().
+		SetColorRole(ColorInfo).
+		SetTitle(b.T("Your preferences")).
+		AddField(b.T("Preference  Value  (set by)"), Example(strings.Join(lines, "\n"))).
+		SetFooter(b.T("Change them with !prefs set {name} {value}")).MessageEmbed
+	b.PrintEmbedToDiscord(emb)
+	return nil
+}
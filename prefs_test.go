@@ -0,0 +1,61 @@
+package haynesbot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPreferenceParse(t *testing.T) {
+	tests := []struct {
+		name, value, want string
+		ok                bool
+	}{
+		{"format", "Compact", "compact", true},
+		{"format", "tiny", "", false},
+		{"palette", "custom 0:#000000 100:#ffffff", "", false},
+		{"palette", "custom 0:#000000, 100:#ffffff", "custom:0:#000000,100:#ffffff", true},
+		{"timezone", "Europe/Berlin", "Europe/Berlin", true},
+		{"timezone", "Mars/Olympus", "", false},
+		{"language", "pt_BR", "pt-br", true},
+		{"language", "xx", "", false},
+	}
+
+	defer useCatalogs(map[string]*Catalog{"pt": {Name: "Português", Messages: map[string]Translation{"Moves for %s": {"other": "Ataques de %s"}}}})()
+
+	for _, test := range tests {
+		pref, _ := GetPreference(test.name)
+		got, err := pref.Parse(test.value)
+		if (err == nil) != test.ok || (test.ok && got != test.want) {
+			t.Errorf("Parse(%s, %q) = %q, %v", test.name, test.value, got, err)
+		}
+	}
+}
+
+func TestPrefResolution(t *testing.T) {
+	guild := &Guild{Settings: GuildSetting{
+		Prefs:        map[string]string{"format": "compact", "league": "ultra"},
+		ChannelPrefs: map[string]map[string]string{"c": {"league": "master"}},
+	}}
+	Users["u"] = &UserSetting{ID: "u", Prefs: map[string]string{"format": "full"}}
+	defer delete(Users, "u")
+
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{ChannelID: "c", Author: &discordgo.User{ID: "u"}}}
+	b := &botResponse{m: m, guild: guild}
+
+	tests := []struct{ name, value, source string }{
+		{"format", "full", ScopeUser},
+		{"league", "master", ScopeChannel},
+		{"palette", "default", ScopeGlobal},
+	}
+	for _, test := range tests {
+		if value, source := b.prefSource(test.name); value != test.value || source != test.source {
+			t.Errorf("%s = %s from %s, want %s from %s", test.name, value, source, test.value, test.source)
+		}
+	}
+
+	m.ChannelID = "other"
+	if value, source := b.prefSource("league"); value != "ultra" || source != ScopeServer {
+		t.Errorf("league = %s from %s, want ultra from server", value, source)
+	}
+}
@@ -0,0 +1,281 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/haynesherway/pogo"
+)
+
+// RankTopSpreads is how many of the best IV spreads !rank lists
+const RankTopSpreads = 5
+
+// PvPChartRows is how many IV spreads !pvpchart shows
+const PvPChartRows = 25
+
+// Rank errors
+var (
+	ERR_RANK_COMMAND     = errors.New("Rank command needs to be formatted like this: !rank {pokemon} {attack iv} {defense iv} {stamina iv} {great|ultra|master|little} {'bb'}")
+	ERR_RANK_CAP         = errors.New("That pokemon is above the CP cap of the league at level 1")
+	ERR_PVPCHART_COMMAND = errors.New("PvP chart command needs to be formatted like this: !pvpchart {pokemon} {great|ultra|master|little} {floor} {'svg'}")
+)
+
+// League is a PvP league with a CP cap, master league has none
+type League struct {
+	Name  string
+	Title string
+	CPCap int
+}
+
+var leagues = []League{
+	{"little", "Little League", 500},
+	{"great", "Great League", 1500},
+	{"ultra", "Ultra League", 2500},
+	{"master", "Master League", 0},
+}
+
+// LeagueNames returns the names of the leagues
+func LeagueNames() []string {
+	names := []string{}
+	for _, league := range leagues {
+		names = append(names, league.Name)
+	}
+	return names
+}
+
+// GetLeague finds a league by name
+func GetLeague(name string) (League, bool) {
+	name = strings.TrimSuffix(strings.ToLower(name), "league")
+	for _, league := range leagues {
+		if league.Name == name {
+			return league, true
+		}
+	}
+	return League{}, false
+}
+
+// PvPSpread is an IV spread at the highest level it can reach in a league
+type PvPSpread struct {
+	Attack, Defense, Stamina int
+	Level                    float64
+	CP                       int
+	StatProduct              float64
+	Rank                     int
+	Percent                  float64
+}
+
+// IVs prints the spread like 0/15/14
+func (s PvPSpread) IVs() string {
+	return fmt.Sprintf("%d/%d/%d", s.Attack, s.Defense, s.Stamina)
+}
+
+// StatProduct returns attack times defense times HP of a pokemon at a level, which is how bulky and strong it is in PvP
+func StatProduct(stats pogo.Stats, level float64, attack, defense, stamina int) float64 {
+	cpm := CPMultiplier(level)
+	return float64(stats.BaseAttack+attack) * cpm * float64(stats.BaseDefense+defense) * cpm * float64(CalcHP(stats, level, stamina))
+}
+
+// bestLevel returns the highest level up to maxLevel where a spread stays under the CP cap
+func bestLevel(stats pogo.Stats, league League, maxLevel float64, attack, defense, stamina int) (float64, bool) {
+	for level := maxLevel; level >= MinLevel; level -= 0.5 {
+		if league.CPCap == 0 || CalcCP(stats, level, attack, defense, stamina) <= league.CPCap {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// ivFloors are the lowest IVs pokemon can have depending on how they were caught
+var ivFloors = map[string]int{
+	"wild":        0,
+	"trade":       1,
+	"goodfriend":  1,
+	"greatfriend": 2,
+	"ultrafriend": 3,
+	"weather":     4,
+	"bestfriend":  5,
+	"raid":        10,
+	"egg":         10,
+	"research":    10,
+	"lucky":       12,
+}
+
+// ParseFloor reads an IV floor, either a number from 0 to 15 or how the pokemon was caught like raid or lucky
+func ParseFloor(s string) (int, bool) {
+	if floor, ok := ivFloors[strings.ToLower(s)]; ok {
+		return floor, true
+	}
+	floor, err := strconv.Atoi(s)
+	return floor, err == nil && floor >= 0 && floor <= 15
+}
+
+// RankSpreads ranks every IV spread of a pokemon at or above an IV floor in a league by stat product, best first
+func RankSpreads(stats pogo.Stats, league League, maxLevel float64, floor int) []PvPSpread {
+	spreads := []PvPSpread{}
+	for a := floor; a <= 15; a++ {
+		for d := floor; d <= 15; d++ {
+			for s := floor; s <= 15; s++ {
+				level, ok := bestLevel(stats, league, maxLevel, a, d, s)
+				if !ok {
+					continue
+				}
+				spreads = append(spreads, PvPSpread{
+					Attack: a, Defense: d, Stamina: s,
+					Level:       level,
+					CP:          CalcCP(stats, level, a, d, s),
+					StatProduct: StatProduct(stats, level, a, d, s),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(spreads, func(i, j int) bool { return spreads[i].StatProduct > spreads[j].StatProduct })
+	for i := range spreads {
+		spreads[i].Rank = i + 1
+		spreads[i].Percent = spreads[i].StatProduct / spreads[0].StatProduct * 100
+	}
+	return spreads
+}
+
+// formatPercent prints a stat product percentage without rounding 99.999 up to 100
+func formatPercent(percent float64) string {
+	return fmt.Sprintf("%.2f%%", math.Floor(percent*100)/100)
+}
+
+// PrintRankToDiscord prints how an IV spread ranks in a PvP league
+func PrintRankToDiscord(b *botResponse) error {
+	fields, buddy := splitBestBuddy(b.fields)
+	if len(fields) < 5 || len(fields) > 6 {
+		return &botError{ERR_RANK_COMMAND, ""}
+	}
+
+	attack, defense, stamina, ok := parseIVs(fields[2:5])
+	if !ok {
+		return &botError{ERR_RANK_COMMAND, ""}
+	}
+
+	leagueName := b.Pref("league")
+	if len(fields) == 6 {
+		leagueName = fields[5]
+	}
+	league, ok := GetLeague(leagueName)
+	if !ok {
+		return &botError{ERR_RANK_COMMAND, ""}
+	}
+
+	p, err := GetPokemon(strings.ToLower(fields[1]))
+	if err != nil {
+		return &botError{ERR_POKEMON_UNRECOGNIZED, fields[1]}
+	}
+	if p.Stats.BaseAttack == 0 {
+		return &botError{ERR_NO_STATS, b.PokemonName(p)}
+	}
+
+	maxLevel := MaxLevel
+	if buddy {
+		maxLevel = MaxBuddyLevel
+	}
+	spreads := RankSpreads(p.Stats, league, maxLevel, 0)
+	if len(spreads) == 0 {
+		return &botError{ERR_RANK_CAP, ""}
+	}
+
+	var spread PvPSpread
+	found := false
+	for _, s := range spreads {
+		if s.Attack == attack && s.Defense == defense && s.Stamina == stamina {
+			spread, found = s, true
+			break
+		}
+	}
+	if !found {
+		return &botError{ERR_RANK_CAP, ""}
+	}
+
+	top := []string{}
+	for i, s := range spreads {
+		if i == RankTopSpreads {
+			break
+		}
+		top = append(top, fmt.Sprintf("#%-4d %-8s %-5s %-5d %s", s.Rank, s.IVs(), formatLevel(s.Level), s.CP, formatPercent(s.Percent)))
+	}
+
+	emb := b.NewEmbed().
+		SetColorRole(ColorResult).
+		SetTitle(fmt.Sprintf("%s %s - %s", b.PokemonName(p), spread.IVs(), b.T(league.Title))).
+		AddField(b.T("Rank"), b.T("#%d of %d (%s of the best)", spread.Rank, len(spreads), formatPercent(spread.Percent))).
+		AddField(b.T("Level"), formatLevel(spread.Level)).
+		AddField("CP", fmt.Sprintf("%d", spread.CP)).
+		AddField(b.T("Stat product"), fmt.Sprintf("%.0f", spread.StatProduct)).
+		AddField(b.T("Best IVs"), Example(strings.Join(top, "\n"))).
+		SetThumbnail(p.API.Sprites.Front)
+	if buddy {
+		emb.SetFooter(b.T("Best buddy, up to level %v", maxLevel))
+	}
+	b.PrintEmbedToDiscord(emb.MessageEmbed)
+	return nil
+}
+
+// PrintPvPChartToDiscord prints a chart of the best IV spreads of a pokemon in a PvP league
+func PrintPvPChartToDiscord(b *botResponse) error {
+	fields, buddy := splitBestBuddy(b.fields)
+	if len(fields) < 2 {
+		return &botError{ERR_PVPCHART_COMMAND, ""}
+	}
+
+	league, _ := GetLeague(b.Pref("league"))
+	floor := 0
+	format := FormatPNG
+	for _, arg := range fields[2:] {
+		if l, ok := GetLeague(arg); ok {
+			league = l
+		} else if f, ok := ParseFloor(arg); ok {
+			floor = f
+		} else if IsTableFormat(strings.ToLower(arg)) {
+			format = strings.ToLower(arg)
+		} else {
+			return &botError{ERR_PVPCHART_COMMAND, ""}
+		}
+	}
+
+	p, err := GetPokemon(strings.ToLower(fields[1]))
+	if err != nil {
+		return &botError{ERR_POKEMON_UNRECOGNIZED, fields[1]}
+	}
+	if p.Stats.BaseAttack == 0 {
+		return &botError{ERR_NO_STATS, b.PokemonName(p)}
+	}
+
+	maxLevel := MaxLevel
+	if buddy {
+		maxLevel = MaxBuddyLevel
+	}
+	spreads := RankSpreads(p.Stats, league, maxLevel, floor)
+	if len(spreads) == 0 {
+		return &botError{ERR_RANK_CAP, ""}
+	}
+
+	table := PvPChartTable(p, league, floor, spreads, b.palette(), b.lang())
+	if b.useImages() || (format == FormatSVG && !b.textOnly()) {
+		b.SendTableToDiscord(fmt.Sprintf("PVPCHART-%s-%s-%d-%v", p.ID, league.Name, floor, maxLevel), table, format)
+		return nil
+	}
+
+	lines := strings.Split(table.Text(), "\n")
+	pages := []*discordgo.MessageEmbed{}
+	for _, page := range SplitRows(lines[1:], PageRows) {
+		emb := b.NewEmbed().
+			SetColorRole(ColorResult).
+			SetDescription(table.Summary).
+			AddField(table.Title, Example(lines[0]+"\n"+strings.Join(page, "\n"))).
+			SetAuthor(b.PokemonName(p), p.API.Sprites.Front).MessageEmbed
+		pages = append(pages, emb)
+	}
+	b.PrintPagesToDiscord(pages)
+	return nil
+}
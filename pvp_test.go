@@ -0,0 +1,97 @@
+package haynesbot
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/haynesherway/pogo"
+)
+
+func TestRankSpreads(t *testing.T) {
+	azumarill := pogo.Stats{BaseAttack: 112, BaseDefense: 152, BaseStamina: 225}
+	great, _ := GetLeague("Great")
+
+	spreads := RankSpreads(azumarill, great, MaxLevel, 0)
+	if len(spreads) != 4096 {
+		t.Fatalf("Got %d spreads, want 4096", len(spreads))
+	}
+	if spreads[0].Rank != 1 || spreads[0].Percent != 100 {
+		t.Errorf("Best spread is %+v", spreads[0])
+	}
+	for _, s := range spreads {
+		if s.CP > great.CPCap {
+			t.Fatalf("%s is CP %d at level %v, over the cap", s.IVs(), s.CP, s.Level)
+		}
+		if s.Level < MaxLevel && CalcCP(azumarill, s.Level+0.5, s.Attack, s.Defense, s.Stamina) <= great.CPCap {
+			t.Fatalf("%s could go up to level %v", s.IVs(), s.Level+0.5)
+		}
+	}
+
+	master, _ := GetLeague("masterleague")
+	if best := RankSpreads(azumarill, master, MaxLevel, 0)[0]; best.IVs() != "15/15/15" {
+		t.Errorf("Best master league spread is %s, want 15/15/15", best.IVs())
+	}
+}
+
+func TestParseFloor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"raid", 10, true},
+		{"Lucky", 12, true},
+		{"4", 4, true},
+		{"16", 16, false},
+		{"shiny", 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := ParseFloor(test.in); ok != test.ok || (ok && got != test.want) {
+			t.Errorf("ParseFloor(%s) = %d, %v", test.in, got, ok)
+		}
+	}
+
+	azumarill := pogo.Stats{BaseAttack: 112, BaseDefense: 152, BaseStamina: 225}
+	great, _ := GetLeague("great")
+	if spreads := RankSpreads(azumarill, great, MaxLevel, 10); len(spreads) != 216 || spreads[0].Percent != 100 {
+		t.Errorf("Got %d spreads with a floor of 10, want 216", len(spreads))
+	}
+}
+
+func TestPvPChartTable(t *testing.T) {
+	azumarill := &pogo.Pokemon{ID: "test-azumarill", Name: "Azumarill", Stats: pogo.Stats{BaseAttack: 112, BaseDefense: 152, BaseStamina: 225}}
+	pictures.Store(azumarill.ID, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	defer pictures.Delete(azumarill.ID)
+	great, _ := GetLeague("great")
+
+	table := PvPChartTable(azumarill, great, 0, RankSpreads(azumarill.Stats, great, MaxLevel, 0), DefaultPalette, DefaultLanguage)
+	if len(table.Rows) != PvPChartRows || !strings.Contains(table.Summary, fmt.Sprintf("Showing the best %d", PvPChartRows)) {
+		t.Errorf("Got %d rows with summary %q, want %d and the limit", len(table.Rows), table.Summary, PvPChartRows)
+	}
+	if last := table.Rows[len(table.Rows)-1]; last.Background == table.Rows[0].Background {
+		t.Errorf("Best and worst rows shown are both %v", last.Background)
+	}
+
+	if table := PvPChartTable(azumarill, great, 0, nil, DefaultPalette, DefaultLanguage); len(table.Rows) != 0 || table.Summary != ERR_RANK_CAP.Error() {
+		t.Errorf("Got %d rows with summary %q for no spreads", len(table.Rows), table.Summary)
+	}
+}
+
+func TestSpreadColorPercent(t *testing.T) {
+	tests := []struct {
+		percent, lowest float64
+		want            int
+	}{
+		{100, 95, 100},
+		{95, 95, 0},
+		{97.5, 95, 50},
+		{100, 100, 100},
+	}
+	for _, test := range tests {
+		if got := spreadColorPercent(test.percent, test.lowest); got != test.want {
+			t.Errorf("spreadColorPercent(%v, %v) = %d, want %d", test.percent, test.lowest, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package haynesbot
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Megabytes of rendered images kept in memory and on disk when the config doesn't say
+const (
+	DefaultRenderCacheSize = 32
+	DefaultRenderDiskSize  = 256
+)
+
+// renderCache keeps rendered images in memory, dropping the least recently used ones when it gets too big.
+// When it has a directory, images are also written to disk and read back after they leave memory.
+// The disk has its own budget, when it's full the files used the longest ago are removed.
+type renderCache struct {
+	sync.Mutex
+	max   int
+	size  int
+	dir   string
+	ll    *list.List
+	items map[string]*list.Element
+
+	diskLock sync.Mutex
+	diskMax  int64
+	diskSize int64
+}
+
+type renderEntry struct {
+	key  string
+	data []byte
+}
+
+var renders = newRenderCache(DefaultRenderCacheSize<<20, "", 0)
+
+func newRenderCache(max int, dir string, diskMax int64) *renderCache {
+	c := &renderCache{
+		max:     max,
+		dir:     dir,
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+		diskMax: diskMax,
+	}
+	if dir != "" {
+		c.pruneDisk()
+	}
+	return c
+}
+
+// Get returns a rendered image, looking on disk when it isn't in memory
+func (c *renderCache) Get(key string) ([]byte, bool) {
+	c.Lock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		c.Unlock()
+		return e.Value.(*renderEntry).data, true
+	}
+	c.Unlock()
+
+	if c.dir == "" {
+		return nil, false
+	}
+	file := path.Join(c.dir, key)
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+
+	// The modification time is when the file was last used, so pruning keeps the popular charts
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+	c.add(key, data)
+	return data, true
+}
+
+// Add stores a rendered image in memory, and on disk when the cache has a directory
+func (c *renderCache) Add(key string, data []byte) {
+	c.add(key, data)
+
+	if c.dir == "" {
+		return
+	}
+	if err := ioutil.WriteFile(path.Join(c.dir, key), data, 0644); err != nil {
+		log.Println("Unable to write rendered image:", err)
+		return
+	}
+
+	c.diskLock.Lock()
+	c.diskSize += int64(len(data))
+	full := c.diskMax > 0 && c.diskSize > c.diskMax
+	c.diskLock.Unlock()
+	if full {
+		c.pruneDisk()
+	}
+}
+
+// pruneDisk removes the files used the longest ago until the directory is under the disk budget
+func (c *renderCache) pruneDisk() {
+	c.diskLock.Lock()
+	defer c.diskLock.Unlock()
+
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		log.Println("Unable to read render cache directory:", err)
+		return
+	}
+
+	c.diskSize = 0
+	for _, f := range files {
+		if !f.IsDir() {
+			c.diskSize += f.Size()
+		}
+	}
+	if c.diskMax <= 0 {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, f := range files {
+		if c.diskSize <= c.diskMax {
+			break
+		}
+		if f.IsDir() {
+			continue
+		}
+		if err := os.Remove(path.Join(c.dir, f.Name())); err != nil {
+			log.Println("Unable to remove rendered image:", err)
+			continue
+		}
+		c.diskSize -= f.Size()
+	}
+}
+
+func (c *renderCache) add(key string, data []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.size -= len(e.Value.(*renderEntry).data)
+		e.Value.(*renderEntry).data = data
+		c.size += len(data)
+		c.ll.MoveToFront(e)
+	} else {
+		c.items[key] = c.ll.PushFront(&renderEntry{key, data})
+		c.size += len(data)
+	}
+
+	for c.size > c.max && c.ll.Len() > 1 {
+		e := c.ll.Back()
+		entry := e.Value.(*renderEntry)
+		c.ll.Remove(e)
+		delete(c.items, entry.key)
+		c.size -= len(entry.data)
+	}
+}
+
+// Len returns how many images are in memory
+func (c *renderCache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.ll.Len()
+}
+
+// initRenderCache sets up the render cache from the config
+func initRenderCache() {
+	size := config.RenderCacheSize
+	if size <= 0 {
+		size = DefaultRenderCacheSize
+	}
+
+	dir := config.RenderCacheDir
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Println("Unable to use render cache directory:", err)
+			dir = ""
+		}
+	}
+
+	diskSize := config.RenderDiskSize
+	if diskSize <= 0 {
+		diskSize = DefaultRenderDiskSize
+	}
+
+	renders = newRenderCache(size<<20, dir, int64(diskSize)<<20)
+}
+
+// DataVersion returns a hash of everything shown in the table, so a chart is rendered again when its data changes
+func (t *Table) DataVersion() string {
+	h := fnv.New64a()
+	writeColor := func(c color.Color) {
+		if c == nil {
+			fmt.Fprint(h, "-;")
+			return
+		}
+		r, g, b, a := c.RGBA()
+		fmt.Fprintf(h, "%x.%x.%x.%x;", r, g, b, a)
+	}
+
+	fmt.Fprintf(h, "%q|%v|%q|%v|", t.Title, t.Picture != nil, t.Headers, t.ColWidths)
+	for _, row := range t.Rows {
+		fmt.Fprintf(h, "%q", row.Cells)
+		writeColor(row.Background)
+		writeColor(row.Color)
+		for _, c := range row.CellBackgrounds {
+			writeColor(c)
+		}
+		fmt.Fprint(h, "|")
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// RenderKey returns the cache key of a table rendered with a palette in a format
+func RenderKey(name string, t *Table, palette Palette, format string) string {
+	return fmt.Sprintf("%s-%s-%s.%s", name, palette.Key(), t.DataVersion(), format)
+}
+
+// Cached returns the table in a format, rendering it only when it isn't cached
+func (t *Table) Cached(key string, format string) ([]byte, error) {
+	if data, ok := renders.Get(key); ok {
+		return data, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Write(buf, format); err != nil {
+		return nil, err
+	}
+	renders.Add(key, buf.Bytes())
+	return buf.Bytes(), nil
+}
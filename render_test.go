@@ -0,0 +1,124 @@
+package haynesbot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRenderCacheEviction(t *testing.T) {
+	c := newRenderCache(10, "", 0)
+	c.Add("a", []byte("1234"))
+	c.Add("b", []byte("1234"))
+	c.Get("a")
+	c.Add("c", []byte("1234"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Least recently used image wasn't evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Recently used image was evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Got %d images in cache, want 2", c.Len())
+	}
+}
+
+func TestRenderKey(t *testing.T) {
+	table := NewTable("test").SetHeaders("IV%")
+	table.AddRow("100%").SetPaletteColors(DefaultPalette, 100)
+	before := RenderKey("RAIDCHART-150", table, DefaultPalette, "png")
+
+	table.AddRow("98%").SetPaletteColors(DefaultPalette, 98)
+	if RenderKey("RAIDCHART-150", table, DefaultPalette, "png") == before {
+		t.Errorf("Key didn't change with the table data")
+	}
+}
+
+func TestRenderPoolBusy(t *testing.T) {
+	// Without workers nothing leaves the queue
+	pool := newRenderPool(0, 1)
+	table := NewTable("test")
+
+	if _, err := pool.submit("a", table, FormatPNG, false); err != nil {
+		t.Fatalf("First chart wasn't queued: %v", err)
+	}
+	if _, err := pool.submit("a", table, FormatPNG, false); err != nil {
+		t.Errorf("Chart already being drawn wasn't shared: %v", err)
+	}
+
+	// A full queue answers right away instead of making the command wait
+	busy := make(chan error)
+	go func() {
+		_, err := pool.Render("b", table, FormatPNG)
+		busy <- err
+	}()
+	select {
+	case err := <-busy:
+		if be, ok := err.(*botError); !ok || be.err != ERR_RENDER_BUSY {
+			t.Errorf("Got %v from a full queue, want ERR_RENDER_BUSY", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Render waited for room in a full queue")
+	}
+
+	// A chart waiting for room gets in when a worker takes the next job
+	queued := make(chan error)
+	go func() {
+		_, err := pool.submit("c", table, FormatPNG, true)
+		queued <- err
+	}()
+	<-pool.jobs
+	if err := <-queued; err != nil {
+		t.Errorf("Waiting chart wasn't queued: %v", err)
+	}
+}
+
+func TestRenderBusyReply(t *testing.T) {
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{ChannelID: "c", Author: &discordgo.User{ID: "u"}}}
+	b := &botResponse{m: m}
+
+	emb := b.errorEmbed(&botError{ERR_RENDER_BUSY, ""})
+	if emb == nil || emb.Description != ERR_RENDER_BUSY.Error() {
+		t.Fatalf("Busy reply = %+v, want %q", emb, ERR_RENDER_BUSY.Error())
+	}
+	if emb.Color != b.theme().Color(ColorWarning) {
+		t.Errorf("Busy reply color = %06x, want the warning color", emb.Color)
+	}
+}
+
+func TestRenderCacheDiskEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "haynesbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newRenderCache(100, dir, 10)
+	c.Add("a", []byte("1234"))
+	c.Add("b", []byte("1234"))
+
+	// Reading a from disk makes it the most recently used file
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "a"), old, old)
+	os.Chtimes(filepath.Join(dir, "b"), old.Add(time.Minute), old.Add(time.Minute))
+	c = newRenderCache(100, dir, 10)
+	c.Get("a")
+	c.Add("c", []byte("1234"))
+
+	if _, err := os.Stat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+		t.Errorf("File used the longest ago wasn't removed")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := os.Stat(filepath.Join(dir, key)); err != nil {
+			t.Errorf("Recently used file %s was removed", key)
+		}
+	}
+	if c.diskSize != 8 {
+		t.Errorf("Disk size is %d, want 8", c.diskSize)
+	}
+}
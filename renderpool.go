@@ -0,0 +1,181 @@
+package haynesbot
+
+import (
+	"errors"
+	"log"
+	"runtime"
+	"sync"
+)
+
+// DefaultRenderQueue is how many charts can wait for a worker when the config doesn't say
+const DefaultRenderQueue = 16
+
+// Render errors
+var (
+	ERR_RENDER_BUSY = errors.New("Chart is being generated, try again in a moment.")
+)
+
+// renderJob is a table waiting to be drawn
+type renderJob struct {
+	key    string
+	table  *Table
+	format string
+}
+
+// renderResult is a drawn table
+type renderResult struct {
+	data []byte
+	err  error
+}
+
+// renderPool draws tables on a fixed number of workers so a burst of charts can't slow down every other command.
+// Jobs for a key that is already being drawn wait for that job instead of drawing it again.
+type renderPool struct {
+	sync.Mutex
+	jobs     chan renderJob
+	inFlight map[string][]chan renderResult
+}
+
+var renderer *renderPool
+
+func newRenderPool(workers int, queue int) *renderPool {
+	pool := &renderPool{
+		jobs:     make(chan renderJob, queue),
+		inFlight: make(map[string][]chan renderResult),
+	}
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+// Render draws a table, returning ERR_RENDER_BUSY right away when the queue is full.
+// Cached tables are returned without using a worker.
+func (pool *renderPool) Render(key string, t *Table, format string) ([]byte, error) {
+	if data, ok := renders.Get(key); ok {
+		return data, nil
+	}
+
+	done, err := pool.submit(key, t, format, false)
+	if err != nil {
+		return nil, err
+	}
+	result := <-done
+	return result.data, result.err
+}
+
+// Wait draws a table, waiting for room in the queue instead of giving up
+func (pool *renderPool) Wait(key string, t *Table, format string) ([]byte, error) {
+	if data, ok := renders.Get(key); ok {
+		return data, nil
+	}
+
+	done, err := pool.submit(key, t, format, true)
+	if err != nil {
+		return nil, err
+	}
+	result := <-done
+	return result.data, result.err
+}
+
+// Warm draws a table into the cache, waiting for room in the queue instead of giving up
+func (pool *renderPool) Warm(key string, t *Table, format string) error {
+	_, err := pool.Wait(key, t, format)
+	return err
+}
+
+// submit queues a table to be drawn. When the queue is full it waits for room if wait is set, or gives up with ERR_RENDER_BUSY.
+func (pool *renderPool) submit(key string, t *Table, format string, wait bool) (chan renderResult, error) {
+	done := make(chan renderResult, 1)
+
+	pool.Lock()
+	if waiting, ok := pool.inFlight[key]; ok {
+		pool.inFlight[key] = append(waiting, done)
+		pool.Unlock()
+		return done, nil
+	}
+	pool.inFlight[key] = []chan renderResult{done}
+	pool.Unlock()
+
+	if wait {
+		pool.jobs <- renderJob{key, t, format}
+		return done, nil
+	}
+	select {
+	case pool.jobs <- renderJob{key, t, format}:
+		return done, nil
+	default:
+		pool.finish(key, renderResult{nil, &botError{ERR_RENDER_BUSY, ""}})
+		return nil, &botError{ERR_RENDER_BUSY, ""}
+	}
+}
+
+func (pool *renderPool) work() {
+	for job := range pool.jobs {
+		data, err := job.table.Cached(job.key, job.format)
+		pool.finish(job.key, renderResult{data, err})
+	}
+}
+
+// finish hands the result to everyone waiting for the key
+func (pool *renderPool) finish(key string, result renderResult) {
+	pool.Lock()
+	waiting := pool.inFlight[key]
+	delete(pool.inFlight, key)
+	pool.Unlock()
+
+	for _, done := range waiting {
+		done <- result
+	}
+}
+
+// initRenderPool starts the render workers from the config
+func initRenderPool() {
+	workers := config.RenderWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	queue := config.RenderQueue
+	if queue <= 0 {
+		queue = DefaultRenderQueue
+	}
+	renderer = newRenderPool(workers, queue)
+}
+
+// warmRenderCache draws the raid charts for the current raid bosses in every palette guilds use and every language
+func warmRenderCache(bosses []string) {
+	palettesInUse := map[string]Palette{DefaultPalette.Key(): DefaultPalette}
+	for _, guild := range Guilds {
+		specs := []string{guild.Pref("palette")}
+		for _, prefs := range guild.Settings.ChannelPrefs {
+			specs = append(specs, prefs["palette"])
+		}
+		for _, spec := range specs {
+			if p, err := ParsePalette(spec); spec != "" && err == nil {
+				palettesInUse[p.Key()] = p
+			}
+		}
+	}
+
+	count := 0
+	for _, boss := range bosses {
+		p, err := GetPokemon(boss)
+		if err != nil {
+			log.Println("Unable to warm raid chart:", boss, err)
+			continue
+		}
+
+		ivList, _ := p.GetRaidCPChart()
+		for _, lang := range Languages() {
+			for _, palette := range palettesInUse {
+				table := RaidChartTable(p, ivList, palette, lang)
+				if err := renderer.Warm(RenderKey("RAIDCHART-"+p.ID, table, palette, FormatPNG), table, FormatPNG); err != nil {
+					log.Println("Unable to warm raid chart:", boss, lang, err)
+					continue
+				}
+				count++
+			}
+		}
+	}
+	log.Printf("Warmed %d raid charts", count)
+}
@@ -0,0 +1,170 @@
+package haynesbot
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ReplyCacheSize is how many command messages the bot remembers its replies for
+const ReplyCacheSize = 500
+
+type replyKind int
+
+// Kinds of replies the bot can send
+const (
+	replyText replyKind = iota
+	replyEmbed
+	replyImage
+)
+
+// trackedReply is a message the bot sent in response to a command
+type trackedReply struct {
+	ChannelID string
+	MessageID string
+	Kind      replyKind
+}
+
+// replyTracker remembers which replies belong to which command message,
+// forgetting the oldest command once it is full
+type replyTracker struct {
+	sync.Mutex
+	size    int
+	order   []string
+	replies map[string][]trackedReply
+}
+
+var replyCache = newReplyTracker(ReplyCacheSize)
+
+func newReplyTracker(size int) *replyTracker {
+	return &replyTracker{
+		size:    size,
+		replies: make(map[string][]trackedReply),
+	}
+}
+
+// Set stores the replies for a command message
+func (rt *replyTracker) Set(commandID string, replies []trackedReply) {
+	rt.Lock()
+	defer rt.Unlock()
+
+	if _, ok := rt.replies[commandID]; !ok {
+		rt.order = append(rt.order, commandID)
+	}
+	rt.replies[commandID] = replies
+
+	for len(rt.order) > rt.size {
+		delete(rt.replies, rt.order[0])
+		rt.order = rt.order[1:]
+	}
+}
+
+// Get returns the replies for a command message
+func (rt *replyTracker) Get(commandID string) ([]trackedReply, bool) {
+	rt.Lock()
+	defer rt.Unlock()
+
+	replies, ok := rt.replies[commandID]
+	return replies, ok
+}
+
+// Remove forgets a command message and returns its replies
+func (rt *replyTracker) Remove(commandID string) ([]trackedReply, bool) {
+	rt.Lock()
+	defer rt.Unlock()
+
+	replies, ok := rt.replies[commandID]
+	if !ok {
+		return nil, false
+	}
+
+	delete(rt.replies, commandID)
+	for i, id := range rt.order {
+		if id == commandID {
+			rt.order = append(rt.order[:i], rt.order[i+1:]...)
+			break
+		}
+	}
+	return replies, true
+}
+
+// Replace swaps a reply of a command message for another one, if the command is still remembered
+func (rt *replyTracker) Replace(commandID string, messageID string, reply trackedReply) {
+	rt.Lock()
+	defer rt.Unlock()
+
+	for i, r := range rt.replies[commandID] {
+		if r.MessageID == messageID {
+			rt.replies[commandID][i] = reply
+			return
+		}
+	}
+}
+
+// messageUpdateHandler re-runs an edited command and edits its previous replies in place
+func messageUpdateHandler(s *discordgo.Session, m *discordgo.MessageUpdate) {
+	previous, ok := replyCache.Get(m.ID)
+	if !ok {
+		return
+	}
+
+	// Updates without an author or content are embeds being added, not edits
+	if m.Author == nil || m.Content == "" {
+		return
+	}
+
+	handleCommand(s, &discordgo.MessageCreate{Message: m.Message}, previous)
+}
+
+// messageDeleteHandler deletes the replies to a command when the command is deleted
+func messageDeleteHandler(s *discordgo.Session, m *discordgo.MessageDelete) {
+	replies, ok := replyCache.Remove(m.ID)
+	if !ok {
+		return
+	}
+
+	for _, r := range replies {
+		_ = s.ChannelMessageDelete(r.ChannelID, r.MessageID)
+	}
+}
+
+// reuseReply returns the next reply from before the command was edited if it can be edited into a reply of this kind.
+// Replies that can't be reused are deleted.
+func (b *botResponse) reuseReply(kind replyKind) (trackedReply, bool) {
+	if len(b.previous) == 0 {
+		return trackedReply{}, false
+	}
+
+	old := b.previous[0]
+	b.previous = b.previous[1:]
+
+	if old.Kind != kind || kind == replyImage {
+		_ = b.s.ChannelMessageDelete(old.ChannelID, old.MessageID)
+		return trackedReply{}, false
+	}
+	return old, true
+}
+
+// track records a reply to the command
+func (b *botResponse) track(m *discordgo.Message, kind replyKind) {
+	if m == nil {
+		return
+	}
+	b.replies = append(b.replies, trackedReply{m.ChannelID, m.ID, kind})
+}
+
+// finishReplies deletes replies from before an edit that weren't reused and remembers the new replies
+func (b *botResponse) finishReplies() {
+	for _, old := range b.previous {
+		_ = b.s.ChannelMessageDelete(old.ChannelID, old.MessageID)
+	}
+	b.previous = nil
+
+	b.scheduleExpiry()
+
+	if len(b.replies) == 0 {
+		replyCache.Remove(b.m.ID)
+		return
+	}
+	replyCache.Set(b.m.ID, b.replies)
+}
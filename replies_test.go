@@ -0,0 +1,25 @@
+package haynesbot
+
+import "testing"
+
+func TestReplyTrackerEviction(t *testing.T) {
+	rt := newReplyTracker(2)
+	rt.Set("1", []trackedReply{{"c", "r1", replyText}})
+	rt.Set("2", []trackedReply{{"c", "r2", replyEmbed}})
+	rt.Set("3", []trackedReply{{"c", "r3", replyImage}})
+
+	if _, ok := rt.Get("1"); ok {
+		t.Error("oldest command should have been evicted")
+	}
+	if replies, ok := rt.Get("3"); !ok || replies[0].MessageID != "r3" {
+		t.Errorf("Get(3) = %v, %v", replies, ok)
+	}
+
+	if _, ok := rt.Remove("2"); !ok {
+		t.Error("Remove(2) should find the command")
+	}
+	rt.Set("4", nil)
+	if _, ok := rt.Get("3"); !ok {
+		t.Error("removed commands shouldn't count towards the cache size")
+	}
+}
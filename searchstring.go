@@ -0,0 +1,292 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/haynesherway/pogo"
+)
+
+// SearchStringLimit is the longest search string the bot gives out, longer ones are split so they're easy to paste
+const SearchStringLimit = 250
+
+// Default number of IV spreads a search string finds
+const (
+	DefaultSearchTop = 10
+	MaxSearchTop     = 200
+)
+
+// Search string errors
+var (
+	ERR_SEARCHSTRING_COMMAND = errors.New("Search string command needs to be formatted like this: !searchstring {pokemon} {great|ultra|master|little|hundo|raid} {top}")
+)
+
+// searchStats are the names the game uses for stats in searches
+var searchStats = []string{"attack", "defense", "hp"}
+
+// starBucket returns the appraisal bar an IV is in, which is all the game can search for: 0, 1-5, 6-10, 11-14 or 15
+func starBucket(iv int) int {
+	switch {
+	case iv == 0:
+		return 0
+	case iv <= 5:
+		return 1
+	case iv <= 10:
+		return 2
+	case iv <= 14:
+		return 3
+	}
+	return 4
+}
+
+// searchCell is an attack, defense and hp appraisal bar
+type searchCell [3]int
+
+// searchBox is a range of appraisal bars for each stat
+type searchBox [3][2]int
+
+// cells returns every cell in the box
+func (box searchBox) cells() []searchCell {
+	cells := []searchCell{}
+	for a := box[0][0]; a <= box[0][1]; a++ {
+		for d := box[1][0]; d <= box[1][1]; d++ {
+			for h := box[2][0]; h <= box[2][1]; h++ {
+				cells = append(cells, searchCell{a, d, h})
+			}
+		}
+	}
+	return cells
+}
+
+// searchTerm prints a search for a range of bars of a stat, like 3-4attack
+func searchTerm(stat int, lo int, hi int) string {
+	if lo == hi {
+		return strconv.Itoa(lo) + searchStats[stat]
+	}
+	return fmt.Sprintf("%d-%d%s", lo, hi, searchStats[stat])
+}
+
+// boundingBox returns the smallest box around cells
+func boundingBox(cells map[searchCell]bool) searchBox {
+	box := searchBox{{4, 0}, {4, 0}, {4, 0}}
+	for cell := range cells {
+		for i := range cell {
+			if cell[i] < box[i][0] {
+				box[i][0] = cell[i]
+			}
+			if cell[i] > box[i][1] {
+				box[i][1] = cell[i]
+			}
+		}
+	}
+	return box
+}
+
+// growBox grows a box from a cell as far as it can go inside bounds without reaching a wanted cell
+func growBox(cell searchCell, bounds searchBox, wanted map[searchCell]bool) searchBox {
+	box := searchBox{{cell[0], cell[0]}, {cell[1], cell[1]}, {cell[2], cell[2]}}
+	free := func(b searchBox) bool {
+		for _, c := range b.cells() {
+			if wanted[c] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for grown := true; grown; {
+		grown = false
+		for i := range box {
+			for _, step := range []int{-1, 1} {
+				next := box
+				if step < 0 && next[i][0] > bounds[i][0] {
+					next[i][0]--
+				} else if step > 0 && next[i][1] < bounds[i][1] {
+					next[i][1]++
+				} else {
+					continue
+				}
+				if free(next) {
+					box, grown = next, true
+				}
+			}
+		}
+	}
+	return box
+}
+
+// searchString builds a search that finds every wanted cell and as few others as it can.
+// The game reads "," as or and "&" as and, so the string limits each stat to the range of the wanted cells,
+// then leaves out boxes of unwanted cells in that range with clauses like !0-1attack,!4hp.
+func searchString(prefix []string, wanted map[searchCell]bool) string {
+	bounds := boundingBox(wanted)
+	if len(wanted) == 1 && wanted[searchCell{4, 4, 4}] {
+		return strings.Join(append(append([]string{}, prefix...), "4*"), "&")
+	}
+
+	terms := append([]string{}, prefix...)
+	for i := range bounds {
+		if bounds[i][0] > 0 || bounds[i][1] < 4 {
+			terms = append(terms, searchTerm(i, bounds[i][0], bounds[i][1]))
+		}
+	}
+
+	covered := map[searchCell]bool{}
+	for _, cell := range bounds.cells() {
+		if wanted[cell] || covered[cell] {
+			continue
+		}
+		box := growBox(cell, bounds, wanted)
+		for _, c := range box.cells() {
+			covered[c] = true
+		}
+
+		clause := []string{}
+		for i := range box {
+			if box[i] != bounds[i] {
+				clause = append(clause, "!"+searchTerm(i, box[i][0], box[i][1]))
+			}
+		}
+		terms = append(terms, strings.Join(clause, ","))
+	}
+	return strings.Join(terms, "&")
+}
+
+// SearchStrings builds the in-game searches that find IV spreads, split into more than one when a search gets too long
+func SearchStrings(prefix []string, spreads []PvPSpread) []string {
+	wanted := map[searchCell]bool{}
+	for _, s := range spreads {
+		wanted[searchCell{starBucket(s.Attack), starBucket(s.Defense), starBucket(s.Stamina)}] = true
+	}
+
+	if search := searchString(prefix, wanted); len(search) <= SearchStringLimit {
+		return []string{search}
+	}
+
+	// Split by attack bar, and by single cell when that's still too long
+	byAttack := map[int]map[searchCell]bool{}
+	for cell := range wanted {
+		if byAttack[cell[0]] == nil {
+			byAttack[cell[0]] = map[searchCell]bool{}
+		}
+		byAttack[cell[0]][cell] = true
+	}
+	attacks := []int{}
+	for a := range byAttack {
+		attacks = append(attacks, a)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(attacks)))
+
+	searches := []string{}
+	for _, a := range attacks {
+		if search := searchString(prefix, byAttack[a]); len(search) <= SearchStringLimit {
+			searches = append(searches, search)
+			continue
+		}
+		for _, cell := range boundingBox(byAttack[a]).cells() {
+			if byAttack[a][cell] {
+				searches = append(searches, searchString(prefix, map[searchCell]bool{cell: true}))
+			}
+		}
+	}
+	return searches
+}
+
+// topSpreads returns every IV spread ranked by how good it is for hundos or raids, best first
+func topSpreads(mode string) []PvPSpread {
+	spreads := []PvPSpread{}
+	for a := 0; a <= 15; a++ {
+		for d := 0; d <= 15; d++ {
+			for s := 0; s <= 15; s++ {
+				spreads = append(spreads, PvPSpread{Attack: a, Defense: d, Stamina: s, Percent: float64(IVPercent(a, d, s))})
+			}
+		}
+	}
+
+	// Raid attackers need attack the most, hundos only care about the total
+	sort.SliceStable(spreads, func(i, j int) bool {
+		if mode == "raid" && spreads[i].Attack != spreads[j].Attack {
+			return spreads[i].Attack > spreads[j].Attack
+		}
+		return spreads[i].Attack+spreads[i].Defense+spreads[i].Stamina > spreads[j].Attack+spreads[j].Defense+spreads[j].Stamina
+	})
+	for i := range spreads {
+		spreads[i].Rank = i + 1
+	}
+	return spreads
+}
+
+// searchName returns what finds a pokemon in a search. The pokedex number works in every language the game is in,
+// the name only in the one it's written in.
+func searchName(b *botResponse, p *pogo.Pokemon) string {
+	if n, ok := PokedexNumber(p); ok {
+		return strconv.Itoa(n)
+	}
+	return strings.ToLower(b.PokemonName(p))
+}
+
+// PrintSearchStringToDiscord prints in-game searches that find the best IV spreads of a pokemon for a league, hundos or raids
+func PrintSearchStringToDiscord(b *botResponse) error {
+	if len(b.fields) < 3 || len(b.fields) > 4 {
+		return &botError{ERR_SEARCHSTRING_COMMAND, ""}
+	}
+
+	p, err := GetPokemon(strings.ToLower(b.fields[1]))
+	if err != nil {
+		return &botError{ERR_POKEMON_UNRECOGNIZED, b.fields[1]}
+	}
+
+	mode := strings.ToLower(b.fields[2])
+	top := DefaultSearchTop
+	if mode == "hundo" {
+		top = 1
+	}
+	if len(b.fields) == 4 {
+		if top, err = strconv.Atoi(b.fields[3]); err != nil || top < 1 || top > MaxSearchTop {
+			return &botError{ERR_SEARCHSTRING_COMMAND, ""}
+		}
+	}
+
+	prefix := []string{searchName(b, p)}
+	title := b.T("Hundos")
+	var spreads []PvPSpread
+	switch mode {
+	case "hundo", "raid":
+		spreads = topSpreads(mode)
+		if mode == "raid" {
+			title = b.T("Raid attackers")
+		}
+	default:
+		league, ok := GetLeague(mode)
+		if !ok {
+			return &botError{ERR_SEARCHSTRING_COMMAND, ""}
+		}
+		if p.Stats.BaseAttack == 0 {
+			return &botError{ERR_NO_STATS, b.PokemonName(p)}
+		}
+		spreads = RankSpreads(p.Stats, league, MaxLevel, 0)
+		if league.CPCap > 0 {
+			prefix = append(prefix, fmt.Sprintf("cp-%d", league.CPCap))
+		}
+		title = b.T(league.Title)
+	}
+	if top < len(spreads) {
+		spreads = spreads[:top]
+	}
+
+	emb := b.NewEmbed().
+		SetColorRole(ColorResult).
+		SetTitle(fmt.Sprintf("%s - %s", b.PokemonName(p), title)).
+		SetDescription(b.Tn("Finds the best IV spread. The game only searches by appraisal bars, so check what it finds with !rank.",
+			"Finds the top %d IV spreads. The game only searches by appraisal bars, so check what it finds with !rank.", len(spreads))).
+		SetThumbnail(p.API.Sprites.Front)
+	searches := SearchStrings(prefix, spreads)
+	for i, search := range searches {
+		emb.AddField(b.T("Search %d of %d", i+1, len(searches)), Example(search))
+	}
+	b.PrintEmbedToDiscord(emb.MessageEmbed)
+	return nil
+}
@@ -0,0 +1,92 @@
+package haynesbot
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/haynesherway/pogo"
+)
+
+// searchMatches checks if a cell is found by a search, skipping terms that aren't about stats
+func searchMatches(search string, cell searchCell) bool {
+	for _, clause := range strings.Split(search, "&") {
+		if clause == "4*" {
+			if cell != (searchCell{4, 4, 4}) {
+				return false
+			}
+			continue
+		}
+		found, stats := false, false
+		for _, term := range strings.Split(clause, ",") {
+			not := strings.HasPrefix(term, "!")
+			term = strings.TrimPrefix(term, "!")
+			for i, stat := range searchStats {
+				if !strings.HasSuffix(term, stat) {
+					continue
+				}
+				stats = true
+				bars := strings.Split(strings.TrimSuffix(term, stat), "-")
+				lo, _ := strconv.Atoi(bars[0])
+				hi, _ := strconv.Atoi(bars[len(bars)-1])
+				if in := cell[i] >= lo && cell[i] <= hi; in != not {
+					found = true
+				}
+			}
+		}
+		if stats && !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchStrings(t *testing.T) {
+	if got := SearchStrings([]string{"mewtwo"}, []PvPSpread{{Attack: 15, Defense: 15, Stamina: 15}}); len(got) != 1 || got[0] != "mewtwo&4*" {
+		t.Errorf("Hundo search is %q, want mewtwo&4*", got)
+	}
+
+	azumarill := []PvPSpread{{Attack: 0, Defense: 15, Stamina: 15}, {Attack: 1, Defense: 15, Stamina: 15}}
+	if got := SearchStrings([]string{"azumarill"}, azumarill); len(got) != 1 || got[0] != "azumarill&0-1attack&4defense&4hp" {
+		t.Errorf("Azumarill search is %q", got)
+	}
+
+	for _, mode := range []string{"hundo", "raid"} {
+		for _, top := range []int{5, 40, 200} {
+			spreads := topSpreads(mode)[:top]
+			wanted := map[searchCell]bool{}
+			for _, s := range spreads {
+				wanted[searchCell{starBucket(s.Attack), starBucket(s.Defense), starBucket(s.Stamina)}] = true
+			}
+
+			searches := SearchStrings([]string{"mewtwo"}, spreads)
+			for _, search := range searches {
+				if len(search) > SearchStringLimit {
+					t.Errorf("%s top %d: search is %d long", mode, top, len(search))
+				}
+			}
+			for _, cell := range (searchBox{{0, 4}, {0, 4}, {0, 4}}).cells() {
+				found := false
+				for _, search := range searches {
+					found = found || searchMatches(search, cell)
+				}
+				if found != wanted[cell] {
+					t.Errorf("%s top %d: %v found is %v, want %v in %q", mode, top, cell, found, wanted[cell], searches)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchName(t *testing.T) {
+	if err := LoadPokedex("bot/pokemonNames.csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &botResponse{}
+	for _, p := range []*pogo.Pokemon{{ID: "mewtwo", Name: "Mewtwo"}, {ID: "mewtwo-a", Name: "Mewtwo A"}, {Name: "Mewtwo"}} {
+		if got := searchName(b, p); got != "150" {
+			t.Errorf("searchName(%s) = %s, want 150", p.ID, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package haynesbot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Table output formats
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// IsTableFormat checks if a format is one tables can be written in
+func IsTableFormat(format string) bool {
+	return format == FormatPNG || format == FormatSVG
+}
+
+// Write writes the table in a format
+func (t *Table) Write(w io.Writer, format string) error {
+	if format == FormatSVG {
+		return t.WriteSVG(w)
+	}
+	return t.WritePNG(w)
+}
+
+// WriteSVG writes the table as an svg image. Unlike png tables, cell backgrounds are drawn.
+func (t *Table) WriteSVG(w io.Writer) error {
+	widths := t.pixelWidths()
+	width := 0
+	for _, cw := range widths {
+		width += cw
+	}
+
+	titleHeight := TableRowHeight
+	if t.Picture != nil {
+		titleHeight = TablePictureHeight
+	}
+	rows := len(t.Rows)
+	if len(t.Headers) > 0 {
+		rows++
+	}
+	height := titleHeight + rows*TableRowHeight
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%d">`+"\n",
+		width, height, width, height, TableFontSize)
+
+	// Title
+	fmt.Fprintf(out, `<rect x="0" y="0" width="%d" height="%d" fill="%s"/>`+"\n", width, titleHeight, svgColor(BLACK))
+	if t.Picture != nil {
+		buf := &bytes.Buffer{}
+		if err := png.Encode(buf, t.Picture); err != nil {
+			return err
+		}
+		b := t.Picture.Bounds()
+		fmt.Fprintf(out, `<image x="%d" y="0" width="%d" height="%d" href="data:image/png;base64,%s"/>`+"\n",
+			(width-b.Dx())/2, b.Dx(), b.Dy(), base64.StdEncoding.EncodeToString(buf.Bytes()))
+	} else {
+		fmt.Fprintf(out, `<text x="%d" y="%d" fill="%s" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			width/2, titleHeight/2, svgColor(WHITE), svgEscape(t.Title))
+	}
+
+	y := titleHeight
+	writeRow := func(cells []string, bg color.Color, fg color.Color, cellBgs []color.Color) {
+		fmt.Fprintf(out, `<rect x="0" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", y, width, TableRowHeight, svgColor(bg))
+		x := 0
+		for i, cw := range widths {
+			if i < len(cellBgs) && cellBgs[i] != nil {
+				fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, y, cw, TableRowHeight, svgColor(cellBgs[i]))
+			}
+			fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="%s"/>`+"\n", x, y, cw, TableRowHeight, svgColor(BLACK))
+			if i < len(cells) {
+				fmt.Fprintf(out, `<text x="%d" y="%d" fill="%s" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+					x+cw/2, y+TableRowHeight/2, svgColor(fg), svgEscape(cells[i]))
+			}
+			x += cw
+		}
+		y += TableRowHeight
+	}
+
+	if len(t.Headers) > 0 {
+		writeRow(t.Headers, BLACK, WHITE, nil)
+	}
+	for _, row := range t.Rows {
+		bg, fg := row.Background, row.Color
+		if bg == nil {
+			bg = WHITE
+		}
+		if fg == nil {
+			fg = BLACK
+		}
+		writeRow(row.Cells, bg, fg, row.CellBackgrounds)
+	}
+
+	fmt.Fprintln(out, "</svg>")
+	return out.Flush()
+}
+
+// svgColor returns a color as #rrggbb
+func svgColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func svgEscape(s string) string {
+	buf := &bytes.Buffer{}
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,35 @@
+package haynesbot
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteSVG(t *testing.T) {
+	table := NewTable("Mewtwo <Raid>").SetHeaders("IV%", "CP@20")
+	table.AddRow("100%", "2387").SetPaletteColors(DefaultPalette, 100)
+
+	buf := &bytes.Buffer{}
+	if err := table.WriteSVG(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The svg has to be valid xml for browsers to show it
+	d := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		_, err := d.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("Invalid svg: %v", err)
+			}
+			break
+		}
+	}
+
+	if !strings.Contains(buf.String(), svgColor(PercentColor(100))) {
+		t.Errorf("Row isn't colored with the palette")
+	}
+}
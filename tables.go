@@ -1,17 +1,25 @@
 package haynesbot
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
 	"image/png"
 	"io"
+	"log"
+	"math"
 
-	//"errors"
+	"errors"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/haynesherway/pngtable"
 	"github.com/haynesherway/pogo"
 	"github.com/nfnt/resize"
@@ -23,6 +31,20 @@ var (
 	WHITE = image.White
 )
 
+// Table errors
+var (
+	ERR_IMAGES_COMMAND = errors.New("Choose how charts are shown using !images {on|off}. Add 'channel' or 'server' to set it for everyone.")
+)
+
+// Table rendering sizes
+const (
+	TableRowHeight     = 15
+	TableFontSize      = 10
+	TablePictureHeight = 100
+	TableCharWidth     = 7
+	TableCellPadding   = 10
+)
+
 // PercentColor returns a color based on a percentage
 func PercentColor(x int) color.Color {
 	m := 12
@@ -46,58 +68,480 @@ func PercentColor(x int) color.Color {
 	return color.RGBA{uint8(red), uint8(green), blue, uint8(255)}
 }
 
-// GetTable gets a png table based on the pokemon stats
-func GetTable(p *pogo.Pokemon, data interface{}, fileName string) *os.File {
+// Table is a table of results any command can produce, to be rendered as an image or as text
+type Table struct {
+	Title     string
+	Picture   image.Image
+	Headers   []string
+	Rows      []*TableRow
+	ColWidths []int
+
+	// Summary is a short description of the table for people who can't see the image
+	Summary string
+}
+
+// TableRow is a row in a table. Colors left nil use the table defaults.
+type TableRow struct {
+	Cells      []string
+	Background color.Color
+	Color      color.Color
+
+	// CellBackgrounds overrides the background of single cells, nil entries use the row background
+	CellBackgrounds []color.Color
+}
+
+// NewTable creates a table with a title
+func NewTable(title string) *Table {
+	return &Table{Title: title}
+}
+
+// SetPicture sets a picture to show above the table instead of the title
+func (t *Table) SetPicture(img image.Image) *Table {
+	t.Picture = img
+	return t
+}
+
+// SetSummary sets the short description sent with the image of the table
+func (t *Table) SetSummary(summary string) *Table {
+	t.Summary = summary
+	return t
+}
+
+// SetHeaders sets the column headers
+func (t *Table) SetHeaders(headers ...string) *Table {
+	t.Headers = headers
+	return t
+}
+
+// SetColWidths sets the width of each column in pixels
+func (t *Table) SetColWidths(widths ...int) *Table {
+	t.ColWidths = widths
+	return t
+}
+
+// AddRow adds a row of cells to the table
+func (t *Table) AddRow(cells ...string) *TableRow {
+	row := &TableRow{Cells: cells}
+	t.Rows = append(t.Rows, row)
+	return row
+}
+
+// SetBackground sets the background color of the row
+func (r *TableRow) SetBackground(c color.Color) *TableRow {
+	r.Background = c
+	return r
+}
+
+// SetColor sets the text color of the row
+func (r *TableRow) SetColor(c color.Color) *TableRow {
+	r.Color = c
+	return r
+}
+
+// SetCellBackground sets the background color of a single cell
+func (r *TableRow) SetCellBackground(i int, c color.Color) *TableRow {
+	for len(r.CellBackgrounds) <= i {
+		r.CellBackgrounds = append(r.CellBackgrounds, nil)
+	}
+	r.CellBackgrounds[i] = c
+	return r
+}
+
+// SetPaletteColors colors the row by a percentage using a palette, with readable text
+func (r *TableRow) SetPaletteColors(palette Palette, percent int) *TableRow {
+	bg := palette.Color(percent)
+	return r.SetBackground(bg).SetColor(TextColor(bg))
+}
+
+// columns returns the number of columns in the table
+func (t *Table) columns() int {
+	cols := len(t.Headers)
+	for _, row := range t.Rows {
+		if len(row.Cells) > cols {
+			cols = len(row.Cells)
+		}
+	}
+	return cols
+}
+
+// widths returns the column widths in characters
+func (t *Table) widths() []int {
+	widths := make([]int, t.columns())
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			if l := utf8.RuneCountInString(cell); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+	measure(t.Headers)
+	for _, row := range t.Rows {
+		measure(row.Cells)
+	}
+	return widths
+}
+
+// pixelWidths returns the column widths in pixels, measuring the cells when they weren't set
+func (t *Table) pixelWidths() []int {
+	if len(t.ColWidths) >= t.columns() {
+		return t.ColWidths
+	}
+
+	widths := t.widths()
+	for i, w := range widths {
+		widths[i] = w*TableCharWidth + TableCellPadding
+	}
+	return widths
+}
+
+// RenderPNG draws the table. pngtable colors whole rows, so cell backgrounds are painted over the rows after it draws.
+func (t *Table) RenderPNG() image.Image {
 	table := pngtable.New()
 
-	//Get image
-	//f, err := Download(p.API.Sprites.Front, p.Name)
+	if t.Picture != nil {
+		table.SetTitlePicture(t.Picture).SetColor(WHITE).SetBackground(BLACK).SetHeight(TablePictureHeight)
+	} else {
+		table.SetTitle(t.Title).SetColor(WHITE).SetBackground(BLACK)
+	}
+
+	table.Options.SetRowHeight(TableRowHeight)
+	table.Options.SetFontSize(TableFontSize)
+	table.Options.SetBorderColor(BLACK)
+
+	if len(t.Headers) > 0 {
+		table.SetHeaders(t.Headers).SetColor(WHITE)
+	}
+	for _, row := range t.Rows {
+		r := table.AddRow(row.Cells)
+		if row.Background != nil {
+			r.SetBackground(row.Background)
+		}
+		if row.Color != nil {
+			r.SetColor(row.Color)
+		} else {
+			r.SetColor(BLACK)
+		}
+	}
+
+	widths := t.pixelWidths()
+	table.Options.SetColWidths(widths)
+	table.Draw()
+	t.paintCellBackgrounds(table.Image, widths)
+	return table.Image
+}
+
+// paintCellBackgrounds swaps the row background for the cell background in cells that have one.
+// Rows are the bottom of the image, each TableRowHeight tall, and only background pixels change so the text stays.
+func (t *Table) paintCellBackgrounds(img *image.RGBA, widths []int) {
+	bounds := img.Bounds()
+	top := bounds.Max.Y - len(t.Rows)*TableRowHeight
+	for i, row := range t.Rows {
+		rowBg := row.Background
+		if rowBg == nil {
+			rowBg = WHITE
+		}
+		from := color.RGBAModel.Convert(rowBg)
+
+		x := bounds.Min.X
+		for col, width := range widths {
+			if col < len(row.CellBackgrounds) && row.CellBackgrounds[col] != nil {
+				to := color.RGBAModel.Convert(row.CellBackgrounds[col])
+				cell := image.Rect(x, top+i*TableRowHeight, x+width, top+(i+1)*TableRowHeight).Intersect(bounds)
+				for py := cell.Min.Y; py < cell.Max.Y; py++ {
+					for px := cell.Min.X; px < cell.Max.X; px++ {
+						if img.At(px, py) == from {
+							img.Set(px, py, to)
+						}
+					}
+				}
+			}
+			x += width
+		}
+	}
+}
+
+// WritePNG writes the table as a png image
+func (t *Table) WritePNG(w io.Writer) error {
+	return png.Encode(w, t.RenderPNG())
+}
+
+// Text returns the table as aligned text for code blocks
+func (t *Table) Text() string {
+	widths := t.widths()
+	lines := []string{}
+	format := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		return strings.TrimRight(strings.Join(padded, " "), " ")
+	}
+
+	if len(t.Headers) > 0 {
+		lines = append(lines, format(t.Headers))
+	}
+	for _, row := range t.Rows {
+		lines = append(lines, format(row.Cells))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TextTable creates a table from columns of text, like the move or type lists from pogo
+func TextTable(title string, headers []string, columns []string) *Table {
+	t := NewTable(title).SetHeaders(headers...)
+
+	cells := make([][]string, len(columns))
+	rows := 0
+	for i, col := range columns {
+		for _, line := range strings.Split(strings.TrimSpace(col), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				cells[i] = append(cells[i], line)
+			}
+		}
+		if len(cells[i]) > rows {
+			rows = len(cells[i])
+		}
+	}
+
+	for r := 0; r < rows; r++ {
+		row := make([]string, len(columns))
+		for i := range columns {
+			if r < len(cells[i]) {
+				row[i] = cells[i][r]
+			}
+		}
+		t.AddRow(row...).SetBackground(WHITE)
+	}
+	return t
+}
+
+// pictures keeps the resized sprites of pokemon so they're only decoded once
+var pictures sync.Map
+
+// PokemonPicture returns the normal sprite of a pokemon sized for a table title
+func PokemonPicture(p *pogo.Pokemon) image.Image {
+	if img, ok := pictures.Load(p.ID); ok {
+		return img.(image.Image)
+	}
+
 	loc, err := p.GetNormal()
+	if err != nil {
+		return nil
+	}
+
 	f, err := os.Open(loc)
 	if err != nil {
 		fmt.Println(err)
+		return nil
 	}
+	defer f.Close()
 
-	tmpimg, _, err := image.Decode(f)
-	img := resize.Resize(0, 100, tmpimg, resize.MitchellNetravali)
+	img, _, err := image.Decode(f)
 	if err != nil {
-		table.SetTitle(fmt.Sprintf("%s - Raid CP Chart", p.Name)).SetColor(WHITE).SetBackground(BLACK)
-	} else {
-		table.SetTitlePicture(img).SetColor(WHITE).SetBackground(BLACK).SetHeight(100)
+		return nil
 	}
+	img = resize.Resize(0, TablePictureHeight, img, resize.MitchellNetravali)
+	pictures.Store(p.ID, img)
+	return img
+}
 
-	table.Options.SetRowHeight(15)
-	table.Options.SetFontSize(10)
-	table.Options.SetBorderColor(BLACK)
+// RaidChartTable creates the raid CP chart for a pokemon, coloring rows with the palette
+func RaidChartTable(p *pogo.Pokemon, ivList []pogo.IVStat, palette Palette, lang string) *Table {
+	table := NewTable(fmt.Sprintf(Translate(lang, "%s - Raid CP Chart"), LocalName(lang, KindPokemon, p.Name))).
+		SetPicture(PokemonPicture(p)).
+		SetHeaders("IV%", "A", "D", "S", "CP@15", "CP@20", "CP@25").
+		SetColWidths(35, 20, 20, 20, 50, 50, 50)
 
-	if ivList, ok := data.([]pogo.IVStat); ok {
-		table.SetHeaders([]string{"IV%", "A", "D", "S", "CP@15", "CP@20", "CP@25"}).SetColor(WHITE)
-		for _, iv := range ivList {
-			if iv.Percent < 88 {
-				continue
-			}
-			a := strconv.Itoa(iv.Attack)
-			d := strconv.Itoa(iv.Defense)
-			s := strconv.Itoa(iv.Stamina)
-			p := strconv.Itoa(iv.Percent) + "%"
-			cp15 := strconv.Itoa(iv.CP15)
-			cp20 := strconv.Itoa(iv.CP20)
-			cp25 := strconv.Itoa(iv.CP25)
-			table.AddRow([]string{p, a, d, s, cp15, cp20, cp25}).SetBackground(PercentColor(iv.Percent)).SetColor(BLACK)
+	for _, iv := range ivList {
+		if iv.Percent < 88 {
+			continue
+		}
+		a := strconv.Itoa(iv.Attack)
+		d := strconv.Itoa(iv.Defense)
+		s := strconv.Itoa(iv.Stamina)
+		p := strconv.Itoa(iv.Percent) + "%"
+		cp15 := strconv.Itoa(iv.CP15)
+		cp20 := strconv.Itoa(iv.CP20)
+		cp25 := strconv.Itoa(iv.CP25)
+		table.AddRow(p, a, d, s, cp15, cp20, cp25).SetPaletteColors(palette, iv.Percent)
+		if iv.Percent == 100 {
+			table.SetSummary(fmt.Sprintf(Translate(lang, "100%%: CP %d at level 20, CP %d at level 25 (weather boosted)"), iv.CP20, iv.CP25))
 		}
 	}
-	table.Options.SetColWidths([]int{35, 20, 20, 20, 50, 50, 50})
-	table.Draw()
-	f, err = os.Create(ImageServer + "/" + fileName)
+	return table
+}
+
+// RaidIVTable creates the table of possible IVs for a raid pokemon caught at a CP
+func RaidIVTable(p *pogo.Pokemon, cp int, ivList []pogo.IVStat, palette Palette, lang string) *Table {
+	table := NewTable(fmt.Sprintf("%s - CP %d", LocalName(lang, KindPokemon, p.Name), cp)).
+		SetPicture(PokemonPicture(p)).
+		SetHeaders("IV%", "A", "D", "S")
+
+	best := 0
+	for _, iv := range ivList {
+		table.AddRow(strconv.Itoa(iv.Percent)+"%", strconv.Itoa(iv.Attack), strconv.Itoa(iv.Defense), strconv.Itoa(iv.Stamina)).
+			SetPaletteColors(palette, iv.Percent)
+		if iv.Percent > best {
+			best = iv.Percent
+		}
+	}
+	summary := TranslatePlural(lang, "%d possible IV combination, best is %d%%", "%d possible IV combinations, best is %d%%", len(ivList))
+	return table.SetSummary(fmt.Sprintf(summary, len(ivList), best))
+}
+
+// PvPChartTable creates the table of the best IV spreads of a pokemon in a league, coloring rows by stat product with the palette.
+// Only the best PvPChartRows spreads are in it, which the summary says.
+func PvPChartTable(p *pogo.Pokemon, league League, floor int, spreads []PvPSpread, palette Palette, lang string) *Table {
+	title := fmt.Sprintf("%s - %s", LocalName(lang, KindPokemon, p.Name), Translate(lang, league.Title))
+	if floor > 0 {
+		title += fmt.Sprintf(Translate(lang, " (IVs %d+)"), floor)
+	}
+	table := NewTable(title).
+		SetPicture(PokemonPicture(p)).
+		SetHeaders(Translate(lang, "Rank"), "IVs", Translate(lang, "Level"), "CP", "SP%").
+		SetColWidths(40, 65, 40, 45, 60)
+
+	shown := spreads
+	if len(shown) > PvPChartRows {
+		shown = shown[:PvPChartRows]
+	}
+	lowest := 100.0
+	for _, s := range shown {
+		lowest = math.Min(lowest, s.Percent)
+	}
+	for _, s := range shown {
+		table.AddRow(strconv.Itoa(s.Rank), s.IVs(), formatLevel(s.Level), strconv.Itoa(s.CP), formatPercent(s.Percent)).
+			SetPaletteColors(palette, spreadColorPercent(s.Percent, lowest))
+	}
+
+	if len(spreads) == 0 {
+		return table.SetSummary(Translate(lang, ERR_RANK_CAP.Error()))
+	}
+
+	summary := fmt.Sprintf(Translate(lang, "Best is %s at level %v, CP %d, out of %d spreads"), spreads[0].IVs(), spreads[0].Level, spreads[0].CP, len(spreads))
+	if len(spreads) > PvPChartRows {
+		summary += " " + fmt.Sprintf(Translate(lang, "Showing the best %d."), PvPChartRows)
+	}
+	return table.SetSummary(summary)
+}
+
+// spreadColorPercent spreads stat products from the lowest one shown up to 100% over the whole palette.
+// They're almost all above 90%, which the palette would color the same.
+func spreadColorPercent(percent float64, lowest float64) int {
+	if lowest >= 100 {
+		return 100
+	}
+	return int(math.Round((percent - lowest) / (100 - lowest) * 100))
+}
+
+// SendTableToDiscord sends a table to discord as a png or svg image, falling back to text if it can't be drawn.
+// When every worker is busy the user is told right away, and the image follows once it's drawn.
+func (b *botResponse) SendTableToDiscord(name string, t *Table, format string) {
+	if b.textOnly() {
+		b.PrintEmbedToDiscord(b.NewEmbed().SetColorRole(ColorResult).SetDescription(t.Summary).AddField(t.Title, Example(t.Text())).MessageEmbed)
+		return
+	}
+
+	key := RenderKey(name, t, b.palette(), format)
+	data, err := renderer.Render(key, t, format)
+	if be, ok := err.(*botError); ok && be.err == ERR_RENDER_BUSY {
+		busy := b.errorEmbed(err)
+		b.theme().Apply(busy)
+		go b.sendTableLater(b.sendEmbed(busy), key, t, format)
+		return
+	} else if err != nil {
+		log.Println("Unable to render table:", err)
+		b.PrintEmbedToDiscord(b.tableTextEmbed(t))
+		return
+	}
+	b.SendImageToDiscord(key, t.AltText(b.lang()), bytes.NewReader(data))
+}
+
+// sendTableLater waits for a table the renderer was too busy for and sends it as a follow up to the busy reply,
+// which is deleted. If the table can't be drawn the busy reply is edited into the text of the table instead.
+func (b *botResponse) sendTableLater(busy *discordgo.Message, key string, t *Table, format string) {
+	data, err := renderer.Wait(key, t, format)
 	if err != nil {
-		panic(err)
+		log.Println("Unable to render table:", err)
+		if busy == nil {
+			return
+		}
+		emb := b.tableTextEmbed(t)
+		b.theme().Apply(emb)
+		for i, part := range (&Embed{MessageEmbed: emb}).Split() {
+			if i == 0 {
+				_, _ = b.s.ChannelMessageEditEmbed(busy.ChannelID, busy.ID, part)
+			} else {
+				_, _ = b.s.ChannelMessageSendEmbed(busy.ChannelID, part)
+			}
+		}
+		return
 	}
-	defer f.Close()
-	err = png.Encode(f, table.Image)
+
+	m, err := b.s.ChannelFileSendWithMessage(b.m.ChannelID, truncate(t.AltText(b.lang()), MessageLimit), key, bytes.NewReader(data))
+	if err != nil || busy == nil {
+		return
+	}
+	_ = b.s.ChannelMessageDelete(busy.ChannelID, busy.ID)
+
+	// The image takes the place of the busy reply, so editing or deleting the command still finds it
+	replyCache.Replace(b.m.ID, busy.ID, trackedReply{m.ChannelID, m.ID, replyImage})
+	if b.guild != nil {
+		if lifetime := b.guild.ReplyLifetime(b.m.ChannelID); lifetime > 0 {
+			expiring.Schedule(m.ChannelID, m.ID, lifetime)
+		}
+	}
+}
+
+// tableTextEmbed is a table as text, for when it can't be drawn
+func (b *botResponse) tableTextEmbed(t *Table) *discordgo.MessageEmbed {
+	return b.NewEmbed().SetColorRole(ColorResult).AddField(t.Title, Example(t.Text())).MessageEmbed
+}
+
+// ChartTable creates a table from a text chart with one row per line, like the IV charts from pogo.
+// Rows with a percentage are colored with the palette.
+func ChartTable(title string, chart string, palette Palette, lang string) *Table {
+	t := NewTable(title)
+	for i, line := range strings.Split(strings.TrimSpace(chart), "\n") {
+		cells := strings.Fields(line)
+		if len(cells) == 0 {
+			continue
+		}
+		if i == 0 && strings.IndexFunc(line, unicode.IsLetter) >= 0 {
+			t.SetHeaders(cells...)
+			continue
+		}
+
+		row := t.AddRow(cells...).SetBackground(WHITE)
+		for _, cell := range cells {
+			if percent, err := strconv.Atoi(strings.TrimSuffix(cell, "%")); err == nil && strings.HasSuffix(cell, "%") {
+				row.SetPaletteColors(palette, percent)
+				break
+			}
+		}
+	}
+	summary := TranslatePlural(lang, "%d possible IV combination", "%d possible IV combinations", len(t.Rows))
+	return t.SetSummary(fmt.Sprintf(summary, len(t.Rows)))
+}
+
+// useImages returns true if the preferences of the user want image replies
+func (b *botResponse) useImages() bool {
+	return !b.textOnly() && b.Pref("images") == "on"
+}
+
+// SetImagesCommand lets users choose between image and text tables, or owners for a channel or the whole server
+func SetImagesCommand(b *botResponse) error {
+	args, scope := splitScope(b.fields[1:])
+	if len(args) != 1 {
+		return &botError{ERR_IMAGES_COMMAND, ""}
+	}
+
+	msg, err := b.setPref("images", args[0], scope)
 	if err != nil {
-		panic(err)
+		return &botError{ERR_IMAGES_COMMAND, ""}
 	}
-	return f
+	b.PrintToDiscord(msg)
+	return nil
 }
 
 // Download downloads a sprite image to a file to be added to the png
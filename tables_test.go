@@ -1,7 +1,11 @@
 package haynesbot
 
 import (
+    "image"
+    "image/color"
+    "image/draw"
     "strconv"
+    "strings"
     "testing"
     "github.com/haynesherway/pngtable")
 
@@ -12,4 +16,53 @@ func TestTableColors(t *testing.T) {
     }
     table.Draw()
     return
-}
\ No newline at end of file
+}
+
+func TestTableText(t *testing.T) {
+    table := ChartTable("test", "IV% A D S\n100% 15 15 15\n98% 15 14 15", DefaultPalette, DefaultLanguage)
+    if len(table.Headers) != 4 || len(table.Rows) != 2 {
+        t.Fatalf("Got %d headers and %d rows", len(table.Headers), len(table.Rows))
+    }
+    if table.Rows[0].Background == nil {
+        t.Errorf("Row with a percentage wasn't colored")
+    }
+    want := "IV%  A  D  S\n100% 15 15 15\n98%  15 14 15"
+    if got := table.Text(); got != want {
+        t.Errorf("Text() = %q, want %q", got, want)
+    }
+}
+
+func TestTableAltText(t *testing.T) {
+    table := NewTable("Mewtwo - Raid CP Chart").SetHeaders("IV%", "CP@20").SetSummary("100%: CP 2387 at level 20")
+    for _, row := range [][]string{{"100%", "2387"}, {"98%", "2366"}, {"98%", "2370"}, {"96%", "2345"}} {
+        table.AddRow(row...)
+    }
+
+    alt := table.AltText(DefaultLanguage)
+    if !strings.Contains(alt, "100%: CP 2387 at level 20") || !strings.Contains(alt, "Top 3 of 4 rows") {
+        t.Errorf("Alt text missing summary or top rows: %q", alt)
+    }
+    if strings.Contains(alt, "2345") {
+        t.Errorf("Alt text has more than %d rows: %q", AltTextRows, alt)
+    }
+}
+
+func TestPaintCellBackgrounds(t *testing.T) {
+    table := NewTable("test")
+    table.AddRow("100%", "15").SetBackground(WHITE).SetCellBackground(1, PercentColor(100))
+
+    img := image.NewRGBA(image.Rect(0, 0, 20, TableRowHeight))
+    draw.Draw(img, img.Bounds(), image.NewUniform(WHITE), image.Point{}, draw.Src)
+    img.Set(15, 5, BLACK)
+    table.paintCellBackgrounds(img, []int{10, 10})
+
+    if img.At(5, 5) != color.RGBAModel.Convert(WHITE) {
+        t.Errorf("First cell was painted %v", img.At(5, 5))
+    }
+    if img.At(12, 5) != color.RGBAModel.Convert(PercentColor(100)) {
+        t.Errorf("Second cell background = %v, want %v", img.At(12, 5), PercentColor(100))
+    }
+    if img.At(15, 5) != color.RGBAModel.Convert(BLACK) {
+        t.Errorf("Text in the second cell was painted over")
+    }
+}
@@ -0,0 +1,215 @@
+package haynesbot
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Theme errors
+var (
+	ERR_THEME_COMMAND = errors.New("Change the look of haynesbot for your server using !theme {primary|secondary|error} {#color}, !theme {footer|name|icon} {text|url}, !theme show or !theme reset")
+	ERR_INVALID_COLOR = errors.New("Colors need to be hex, like #9013FE")
+	ERR_INVALID_ICON  = errors.New("Icons need to be a link starting with http:// or https://, or none to remove the icon")
+)
+
+// ColorRole is what an embed is used for, which decides its color in a theme
+type ColorRole int
+
+// Color roles for embeds
+const (
+	ColorResult ColorRole = iota
+	ColorInfo
+	ColorWarning
+)
+
+// Theme is how embeds look in a guild. Colors are nil when they aren't set, so black can be chosen.
+type Theme struct {
+	Primary    *int   `json:"Primary,omitempty"`
+	Secondary  *int   `json:"Secondary,omitempty"`
+	Error      *int   `json:"Error,omitempty"`
+	Footer     string `json:"Footer,omitempty"`
+	AuthorName string `json:"AuthorName,omitempty"`
+	AuthorIcon string `json:"AuthorIcon,omitempty"`
+}
+
+// DefaultTheme is used when a guild hasn't set a theme
+var DefaultTheme = Theme{
+	Primary:   ThemeColor(0x9013FE),
+	Secondary: ThemeColor(0x0B9EFF),
+	Error:     ThemeColor(0xD0021B),
+}
+
+// ThemeColor returns a color to set in a theme
+func ThemeColor(clr int) *int {
+	return &clr
+}
+
+// Color returns the color of a role in the theme, falling back to the default theme
+func (t *Theme) Color(role ColorRole) int {
+	if t == nil {
+		t = &DefaultTheme
+	}
+
+	clr, fallback := t.Primary, DefaultTheme.Primary
+	switch role {
+	case ColorInfo:
+		clr, fallback = t.Secondary, DefaultTheme.Secondary
+	case ColorWarning:
+		clr, fallback = t.Error, DefaultTheme.Error
+	}
+	if clr != nil {
+		return *clr
+	}
+	return *fallback
+}
+
+// Apply adds the theme footer and author to an embed that doesn't have them
+func (t *Theme) Apply(e *discordgo.MessageEmbed) {
+	if t == nil {
+		return
+	}
+
+	if t.Footer != "" && e.Footer == nil {
+		e.Footer = &discordgo.MessageEmbedFooter{Text: truncate(t.Footer, EmbedLimitFooter)}
+	}
+
+	// Discord turns down embeds with a bad icon, so one saved before icons were checked is left out
+	icon := t.AuthorIcon
+	if _, err := ParseIcon(icon); err != nil {
+		icon = ""
+	}
+	if (t.AuthorName != "" || icon != "") && e.Author == nil {
+		e.Author = &discordgo.MessageEmbedAuthor{
+			Name:    t.AuthorName,
+			IconURL: icon,
+		}
+	}
+}
+
+// theme returns the theme for the guild the command was sent in
+func (b *botResponse) theme() *Theme {
+	if b.guild == nil {
+		return nil
+	}
+	return b.guild.Settings.Theme
+}
+
+// NewEmbed creates an embed using the theme of the guild the command was sent in, and compact if the user wants
+func (b *botResponse) NewEmbed() *Embed {
+	return NewEmbed().SetTheme(b.theme()).SetCompact(b.compact())
+}
+
+// SetTheme sets the theme for a guild, nil resets it to the default
+func (guild *Guild) SetTheme(t *Theme) error {
+	guild.Settings.Theme = t
+	guildSettings.add(guild).save(config.GuildFile)
+	return nil
+}
+
+// ParseColor reads a hex color like #9013FE or 0x9013FE
+func ParseColor(s string) (int, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(s), "#"), "0x")
+	clr, err := strconv.ParseInt(s, 16, 32)
+	if err != nil || clr < 0 || clr > 0xFFFFFF {
+		return 0, &botError{ERR_INVALID_COLOR, ""}
+	}
+	return int(clr), nil
+}
+
+// ParseIcon reads the link to an icon, which has to be http or https. none removes the icon.
+func ParseIcon(s string) (string, error) {
+	if strings.EqualFold(s, "none") {
+		return "", nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", &botError{ERR_INVALID_ICON, ""}
+	}
+	return u.String(), nil
+}
+
+// SetThemeCommand allows the server owner to change the colors and branding of embeds
+func SetThemeCommand(b *botResponse) error {
+	guild, err := b.ownerGuild()
+	if err != nil {
+		return err
+	}
+
+	if len(b.fields) < 2 {
+		return &botError{ERR_THEME_COMMAND, ""}
+	}
+
+	theme := Theme{}
+	if guild.Settings.Theme != nil {
+		theme = *guild.Settings.Theme
+	}
+
+	setting := strings.ToLower(b.fields[1])
+	switch setting {
+	case "show":
+		return b.printThemePreview()
+	case "reset":
+		guild.SetTheme(nil)
+		b.PrintToDiscord(b.T("Theme reset to the haynesbot default."))
+		return nil
+	}
+
+	if len(b.fields) < 3 {
+		return &botError{ERR_THEME_COMMAND, ""}
+	}
+
+	switch setting {
+	case "primary", "secondary", "error":
+		clr, err := ParseColor(b.fields[2])
+		if err != nil {
+			return err
+		}
+		switch setting {
+		case "primary":
+			theme.Primary = ThemeColor(clr)
+		case "secondary":
+			theme.Secondary = ThemeColor(clr)
+		case "error":
+			theme.Error = ThemeColor(clr)
+		}
+	case "footer":
+		theme.Footer = b.rawArgs(2)
+	case "name":
+		theme.AuthorName = b.rawArgs(2)
+	case "icon":
+		if theme.AuthorIcon, err = ParseIcon(b.fields[2]); err != nil {
+			return err
+		}
+	default:
+		return &botError{ERR_THEME_COMMAND, ""}
+	}
+
+	guild.SetTheme(&theme)
+	return b.printThemePreview()
+}
+
+// printThemePreview prints an embed for each color role so owners can see the theme
+func (b *botResponse) printThemePreview() error {
+	t := b.theme()
+	roles := []struct {
+		name string
+		role ColorRole
+	}{
+		{"Result", ColorResult},
+		{"Info", ColorInfo},
+		{"Warning", ColorWarning},
+	}
+
+	for _, r := range roles {
+		emb := b.NewEmbed().
+			SetColorRole(r.role).
+			AddField(r.name, fmt.Sprintf("#%06X", t.Color(r.role))).MessageEmbed
+		b.PrintEmbedToDiscord(emb)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package haynesbot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseColor(t *testing.T) {
+	for _, s := range []string{"#9013FE", "0x9013fe", "9013FE"} {
+		if clr, err := ParseColor(s); err != nil || clr != 0x9013FE {
+			t.Errorf("ParseColor(%q) = %X, %v", s, clr, err)
+		}
+	}
+
+	for _, s := range []string{"purple", "#1000000", ""} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("ParseColor(%q) should fail", s)
+		}
+	}
+}
+
+func TestThemeColor(t *testing.T) {
+	var noTheme *Theme
+	if clr := noTheme.Color(ColorResult); clr != *DefaultTheme.Primary {
+		t.Errorf("nil theme result color = %X, want %X", clr, *DefaultTheme.Primary)
+	}
+
+	theme := &Theme{Primary: ThemeColor(0xFF5733), Error: ThemeColor(0)}
+	if clr := theme.Color(ColorResult); clr != 0xFF5733 {
+		t.Errorf("result color = %X, want FF5733", clr)
+	}
+	if clr := theme.Color(ColorInfo); clr != *DefaultTheme.Secondary {
+		t.Errorf("unset info color = %X, want default %X", clr, *DefaultTheme.Secondary)
+	}
+	if clr := theme.Color(ColorWarning); clr != 0 {
+		t.Errorf("black warning color = %X, want 0", clr)
+	}
+}
+
+func TestParseIcon(t *testing.T) {
+	for s, want := range map[string]string{"https://example.com/logo.png": "https://example.com/logo.png", "http://example.com/a.png": "http://example.com/a.png", "none": ""} {
+		if got, err := ParseIcon(s); err != nil || got != want {
+			t.Errorf("ParseIcon(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"example.com/logo.png", "ftp://example.com/logo.png", "https://", "javascript:alert(1)", "htps//typo"} {
+		if _, err := ParseIcon(s); err == nil {
+			t.Errorf("ParseIcon(%q) should fail", s)
+		}
+	}
+
+	e := &discordgo.MessageEmbed{}
+	(&Theme{AuthorIcon: "not a link"}).Apply(e)
+	if e.Author != nil {
+		t.Errorf("A saved bad icon was added to the embed: %+v", e.Author)
+	}
+}
+
+func TestErrorEmbed(t *testing.T) {
+	guild := &Guild{Settings: GuildSetting{Theme: &Theme{Error: ThemeColor(0xFF0000)}}}
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{ChannelID: "c", Author: &discordgo.User{ID: "u"}}}
+
+	b := &botResponse{m: m, guild: guild}
+	if emb := b.errorEmbed(&botError{ERR_POKEMON_UNRECOGNIZED, "pikachuu"}); emb == nil || emb.Color != 0xFF0000 {
+		t.Errorf("Error embed = %+v, want the theme error color", emb)
+	}
+	if emb := b.errorEmbed(&botError{ERR_NO_GUILD, ""}); emb != nil {
+		t.Errorf("Missing guild in a server should be silent, got %+v", emb)
+	}
+
+	dm := &botResponse{m: m}
+	if emb := dm.errorEmbed(&botError{ERR_NO_GUILD, ""}); emb == nil || emb.Color != *DefaultTheme.Error {
+		t.Errorf("Missing guild in a direct message = %+v, want an error", emb)
+	}
+}
@@ -0,0 +1,123 @@
+package haynesbot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sync"
+)
+
+// User settings management
+var (
+	userSettings = &UserSettings{UserSettings: make(map[string]*UserSetting)}
+	Users        = userSettings.UserSettings
+	usersLock    sync.Mutex
+)
+
+// UserSettings is a json struct to get the settings of every user
+type UserSettings struct {
+	UserSettings map[string]*UserSetting `json:"UserSettings"`
+}
+
+// UserSetting is a holder for the settings of a single discord user
+type UserSetting struct {
+	ID    string            `json:"ID"`
+	Prefs map[string]string `json:"Prefs,omitempty"`
+}
+
+// GetUser gets the settings for a user, creating them if the user doesn't have any yet
+func GetUser(id string) *UserSetting {
+	usersLock.Lock()
+	defer usersLock.Unlock()
+
+	user, ok := Users[id]
+	if !ok {
+		user = &UserSetting{ID: id}
+		Users[id] = user
+	}
+	return user
+}
+
+// LookupUser gets the settings for a user if the user has any
+func LookupUser(id string) (*UserSetting, bool) {
+	usersLock.Lock()
+	defer usersLock.Unlock()
+
+	user, ok := Users[id]
+	return user, ok
+}
+
+// Pref returns a preference the user set
+func (user *UserSetting) Pref(name string) string {
+	usersLock.Lock()
+	defer usersLock.Unlock()
+	return user.Prefs[name]
+}
+
+// SetPref sets a preference for the user. An empty value resets the preference, and an empty name resets all of them.
+func (user *UserSetting) SetPref(name string, value string) error {
+	usersLock.Lock()
+	switch {
+	case name == "":
+		user.Prefs = nil
+	case value == "":
+		delete(user.Prefs, name)
+	default:
+		if user.Prefs == nil {
+			user.Prefs = make(map[string]string)
+		}
+		user.Prefs[name] = value
+	}
+	usersLock.Unlock()
+
+	return userSettings.save(userFile())
+}
+
+func readUserSettings(f string) error {
+	file, err := ioutil.ReadFile(f)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println(err.Error())
+		}
+		return err
+	}
+
+	settings := &UserSettings{}
+	err = json.Unmarshal(file, settings)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if settings.UserSettings == nil {
+		settings.UserSettings = make(map[string]*UserSetting)
+	}
+	userSettings = settings
+	Users = settings.UserSettings
+
+	return nil
+}
+
+func (us *UserSettings) save(file string) error {
+	usersLock.Lock()
+	defer usersLock.Unlock()
+
+	out, err := json.MarshalIndent(us, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	log.Println("Writing to user settings file...")
+
+	return ioutil.WriteFile(file, out, 0600)
+}
+
+// userFile returns where user settings are saved
+func userFile() string {
+	if config.UserFile != "" {
+		return config.UserFile
+	}
+	return path.Join(path.Dir(config.GuildFile), "usersettings.json")
+}